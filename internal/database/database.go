@@ -54,11 +54,33 @@ func New() Service {
 		&domain.ChapterImage{},
 		&domain.Tag{},
 		&domain.TagTranslation{},
+		&domain.RefreshToken{},
+		&domain.RevokedToken{},
+		&domain.PasswordResetToken{},
+		&domain.EmailVerificationToken{},
+		&domain.RoleRequest{},
+		&domain.RoleChange{},
+		&domain.RecoveryCode{},
+		&domain.LoginAttempt{},
+		&domain.APIKey{},
+		&domain.ExternalIdentity{},
+		&domain.OIDCLoginState{},
+		&domain.Session{},
+		&domain.AdminAuditLog{},
+		&domain.UserPreferences{},
+		&domain.CreatorFollow{},
+		&domain.ComicSubscription{},
+		&domain.Notification{},
+		&domain.NotificationPreference{},
 	)
 	if err != nil {
 		log.Fatal(err)
 	}
 
+	if err := createSearchIndexes(db); err != nil {
+		log.Fatal(err)
+	}
+
 	dbInstance = &service{db: db}
 	return dbInstance
 }
@@ -0,0 +1,29 @@
+package database
+
+import "gorm.io/gorm"
+
+// createSearchIndexes enables pg_trgm and adds the trigram and prefix
+// indexes catalog search and suggestions rely on. The indexed expressions
+// must match the ones in repository/search_repository.go.
+func createSearchIndexes(db *gorm.DB) error {
+	statements := []string{
+		"CREATE EXTENSION IF NOT EXISTS pg_trgm",
+		`CREATE INDEX IF NOT EXISTS idx_comics_search_trgm ON comics USING gin ((
+			COALESCE(title->>'en', '') || ' ' || COALESCE(title->>'th', '') || ' ' ||
+			COALESCE(subtitle->>'en', '') || ' ' || COALESCE(subtitle->>'th', '') || ' ' || COALESCE(author, '') || ' ' ||
+			COALESCE(description->>'en', '') || ' ' || COALESCE(description->>'th', '')
+		) gin_trgm_ops)`,
+		"CREATE INDEX IF NOT EXISTS idx_tag_translations_name_trgm ON tag_translations USING gin (name gin_trgm_ops)",
+		// Prefix indexes for typeahead suggestions.
+		"CREATE INDEX IF NOT EXISTS idx_comics_title_en_prefix ON comics (LOWER(title->>'en') text_pattern_ops)",
+		"CREATE INDEX IF NOT EXISTS idx_comics_title_th_prefix ON comics (LOWER(title->>'th') text_pattern_ops)",
+		"CREATE INDEX IF NOT EXISTS idx_comics_author_prefix ON comics (LOWER(author) text_pattern_ops)",
+		"CREATE INDEX IF NOT EXISTS idx_tag_translations_name_prefix ON tag_translations (LOWER(name) text_pattern_ops)",
+	}
+	for _, statement := range statements {
+		if err := db.Exec(statement).Error; err != nil {
+			return err
+		}
+	}
+	return nil
+}
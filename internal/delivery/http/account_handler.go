@@ -0,0 +1,139 @@
+package http
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/pur108/talestoon-be/internal/domain"
+	"github.com/pur108/talestoon-be/internal/middleware"
+	"github.com/pur108/talestoon-be/internal/usecase"
+)
+
+type AccountHandler struct {
+	accountUsecase usecase.AccountUsecase
+}
+
+func NewAccountHandler(app *fiber.App, accountUsecase usecase.AccountUsecase, authUsecase usecase.AuthUsecase) {
+	handler := &AccountHandler{accountUsecase}
+	protected := middleware.Protected(authUsecase)
+	session := middleware.SessionRequired()
+
+	app.Get("/api/users/me/export", protected, session, handler.Export)
+	app.Post("/api/users/me/deletion", protected, session, handler.RequestDeletion)
+	app.Delete("/api/users/me/deletion", protected, session, handler.CancelDeletion)
+}
+
+// Export downloads the user's data as a single JSON document, or with
+// format=zip as an archive holding one JSON file per section.
+func (h *AccountHandler) Export(c *fiber.Ctx) error {
+	principal, ok := middleware.GetPrincipal(c)
+	if !ok {
+		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{"error": "Unauthorized"})
+	}
+
+	export, err := h.accountUsecase.Export(principal.UserID)
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "Failed to export account data"})
+	}
+
+	filename := fmt.Sprintf("talestoon-export-%s", export.ExportedAt.Format("20060102"))
+
+	switch c.Query("format", "json") {
+	case "json":
+		c.Attachment(filename + ".json")
+		return c.JSON(export)
+	case "zip":
+		archive, err := exportArchive(export)
+		if err != nil {
+			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "Failed to build export archive"})
+		}
+		c.Attachment(filename + ".zip")
+		c.Set(fiber.HeaderContentType, "application/zip")
+		return c.Send(archive)
+	}
+
+	return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Format must be json or zip"})
+}
+
+func exportArchive(export *usecase.AccountExport) ([]byte, error) {
+	sections := []struct {
+		name string
+		data interface{}
+	}{
+		{"profile.json", export.Profile},
+		{"comics.json", export.Comics},
+		{"role_requests.json", export.RoleRequests},
+		{"role_changes.json", export.RoleChanges},
+		{"sessions.json", export.Sessions},
+		{"login_attempts.json", export.LoginAttempts},
+		{"api_keys.json", export.APIKeys},
+		{"identities.json", export.Identities},
+		{"following.json", export.Following},
+		{"subscriptions.json", export.Subscriptions},
+		{"notifications.json", export.Notifications},
+		{"notification_preferences.json", export.NotificationPreferences},
+	}
+
+	var buf bytes.Buffer
+	zw := zip.NewWriter(&buf)
+	for _, section := range sections {
+		w, err := zw.CreateHeader(&zip.FileHeader{Name: section.name, Method: zip.Deflate, Modified: export.ExportedAt})
+		if err != nil {
+			return nil, err
+		}
+		enc := json.NewEncoder(w)
+		enc.SetIndent("", "  ")
+		if err := enc.Encode(section.data); err != nil {
+			return nil, err
+		}
+	}
+	if err := zw.Close(); err != nil {
+		return nil, err
+	}
+
+	return buf.Bytes(), nil
+}
+
+func (h *AccountHandler) RequestDeletion(c *fiber.Ctx) error {
+	type Request struct {
+		Password string `json:"password"`
+		Code     string `json:"code"`
+	}
+
+	principal, ok := middleware.GetPrincipal(c)
+	if !ok {
+		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{"error": "Unauthorized"})
+	}
+
+	var req Request
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Invalid request"})
+	}
+
+	scheduledAt, err := h.accountUsecase.RequestDeletion(principal.UserID, req.Password, req.Code)
+	if err != nil {
+		if errors.Is(err, domain.ErrInvalidCredentials) || errors.Is(err, usecase.ErrInvalidTwoFactorCode) {
+			return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{"error": err.Error()})
+		}
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "Failed to schedule account deletion"})
+	}
+
+	return c.Status(fiber.StatusAccepted).JSON(fiber.Map{"deletion_scheduled_at": scheduledAt})
+}
+
+func (h *AccountHandler) CancelDeletion(c *fiber.Ctx) error {
+	principal, ok := middleware.GetPrincipal(c)
+	if !ok {
+		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{"error": "Unauthorized"})
+	}
+
+	if err := h.accountUsecase.CancelDeletion(principal.UserID); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": err.Error()})
+	}
+
+	return c.SendStatus(fiber.StatusNoContent)
+}
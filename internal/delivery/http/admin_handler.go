@@ -0,0 +1,348 @@
+package http
+
+import (
+	"errors"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+	"github.com/pur108/talestoon-be/internal/domain"
+	"github.com/pur108/talestoon-be/internal/middleware"
+	"github.com/pur108/talestoon-be/internal/usecase"
+)
+
+type AdminHandler struct {
+	adminUsecase usecase.AdminUsecase
+	roleUsecase  usecase.RoleUsecase
+	authUsecase  usecase.AuthUsecase
+}
+
+func NewAdminHandler(app *fiber.App, adminUsecase usecase.AdminUsecase, roleUsecase usecase.RoleUsecase, authUsecase usecase.AuthUsecase) {
+	handler := &AdminHandler{adminUsecase, roleUsecase, authUsecase}
+	group := app.Group("/api/admin", middleware.Protected(authUsecase), middleware.SessionRequired(), middleware.RoleRequired(domain.RoleAdmin))
+	group.Get("/role-requests", handler.ListRoleRequests)
+	group.Post("/role-requests/:id/approve", handler.ApproveRoleRequest)
+	group.Post("/role-requests/:id/deny", handler.DenyRoleRequest)
+	group.Get("/users", handler.ListUsers)
+	group.Get("/users/:id", handler.GetUser)
+	group.Put("/users/:id/role", handler.ChangeRole)
+	group.Post("/users/:id/suspend", handler.SuspendUser)
+	group.Post("/users/:id/ban", handler.BanUser)
+	group.Post("/users/:id/reinstate", handler.ReinstateUser)
+	group.Post("/users/:id/force-password-reset", handler.ForcePasswordReset)
+	group.Get("/users/:id/sessions", handler.ListUserSessions)
+	group.Delete("/users/:id/sessions", handler.RevokeUserSessions)
+	group.Get("/users/:id/role-history", handler.ListRoleHistory)
+	group.Get("/login-attempts", handler.ListLoginAttempts)
+	group.Get("/audit-log", handler.ListAuditLog)
+}
+
+func (h *AdminHandler) ListRoleRequests(c *fiber.Ctx) error {
+	status := domain.RoleRequestStatus(c.Query("status", string(domain.RoleRequestPending)))
+
+	requests, err := h.roleUsecase.ListRequests(status)
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "Failed to fetch role requests"})
+	}
+
+	return c.JSON(requests)
+}
+
+func (h *AdminHandler) ApproveRoleRequest(c *fiber.Ctx) error {
+	return h.reviewRoleRequest(c, h.adminUsecase.ApproveRoleRequest)
+}
+
+func (h *AdminHandler) DenyRoleRequest(c *fiber.Ctx) error {
+	return h.reviewRoleRequest(c, h.adminUsecase.DenyRoleRequest)
+}
+
+func (h *AdminHandler) reviewRoleRequest(c *fiber.Ctx, review func(adminID, requestID uuid.UUID, note string) (*domain.RoleRequest, error)) error {
+	type Request struct {
+		Note string `json:"note"`
+	}
+
+	id, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Invalid request ID"})
+	}
+
+	principal, ok := middleware.GetPrincipal(c)
+	if !ok {
+		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{"error": "Unauthorized"})
+	}
+
+	var req Request
+	if len(c.Body()) > 0 {
+		if err := c.BodyParser(&req); err != nil {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Invalid request"})
+		}
+	}
+
+	request, err := review(principal.UserID, id, req.Note)
+	if err != nil {
+		if errors.Is(err, domain.ErrNotFound) {
+			return c.Status(fiber.StatusNotFound).JSON(fiber.Map{"error": "Role request not found"})
+		}
+		if errors.Is(err, domain.ErrRequestReviewed) {
+			return c.Status(fiber.StatusConflict).JSON(fiber.Map{"error": err.Error()})
+		}
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": err.Error()})
+	}
+
+	return c.JSON(request)
+}
+
+func (h *AdminHandler) ChangeRole(c *fiber.Ctx) error {
+	type Request struct {
+		Role   domain.UserRole `json:"role"`
+		Reason string          `json:"reason"`
+	}
+
+	userID, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Invalid user ID"})
+	}
+
+	principal, ok := middleware.GetPrincipal(c)
+	if !ok {
+		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{"error": "Unauthorized"})
+	}
+
+	var req Request
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Invalid request"})
+	}
+
+	if err := h.adminUsecase.ChangeRole(principal.UserID, userID, req.Role, req.Reason); err != nil {
+		if errors.Is(err, domain.ErrNotFound) {
+			return c.Status(fiber.StatusNotFound).JSON(fiber.Map{"error": "User not found"})
+		}
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": err.Error()})
+	}
+
+	return c.SendStatus(fiber.StatusNoContent)
+}
+
+func (h *AdminHandler) ListRoleHistory(c *fiber.Ctx) error {
+	userID, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Invalid user ID"})
+	}
+
+	changes, err := h.roleUsecase.ListRoleHistory(userID)
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "Failed to fetch role history"})
+	}
+
+	return c.JSON(changes)
+}
+
+func (h *AdminHandler) ListLoginAttempts(c *fiber.Ctx) error {
+	limit := c.QueryInt("limit", 100)
+	if limit <= 0 || limit > 500 {
+		limit = 100
+	}
+
+	attempts, err := h.authUsecase.ListLoginAttempts(c.Query("identifier"), c.Query("ip"), c.QueryBool("failed", true), limit)
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "Failed to fetch login attempts"})
+	}
+
+	return c.JSON(attempts)
+}
+
+// ListUsers searches accounts by q (username, email or display name), role
+// and status, paginated with page and page_size.
+func (h *AdminHandler) ListUsers(c *fiber.Ctx) error {
+	page := c.QueryInt("page", 1)
+	if page < 1 {
+		page = 1
+	}
+	pageSize := c.QueryInt("page_size", 20)
+	if pageSize <= 0 || pageSize > 100 {
+		pageSize = 20
+	}
+
+	users, total, err := h.adminUsecase.ListUsers(domain.UserFilter{
+		Query:  c.Query("q"),
+		Role:   domain.UserRole(c.Query("role")),
+		Status: domain.UserStatus(c.Query("status")),
+		Limit:  pageSize,
+		Offset: (page - 1) * pageSize,
+	})
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "Failed to fetch users"})
+	}
+
+	return c.JSON(fiber.Map{
+		"data":      users,
+		"total":     total,
+		"page":      page,
+		"page_size": pageSize,
+	})
+}
+
+func (h *AdminHandler) GetUser(c *fiber.Ctx) error {
+	userID, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Invalid user ID"})
+	}
+
+	user, err := h.adminUsecase.GetUser(userID)
+	if err != nil {
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{"error": "User not found"})
+	}
+
+	return c.JSON(user)
+}
+
+func (h *AdminHandler) SuspendUser(c *fiber.Ctx) error {
+	return h.restrictUser(c, h.adminUsecase.Suspend)
+}
+
+func (h *AdminHandler) BanUser(c *fiber.Ctx) error {
+	return h.restrictUser(c, h.adminUsecase.Ban)
+}
+
+func (h *AdminHandler) restrictUser(c *fiber.Ctx, restrict func(adminID, userID uuid.UUID, reason string, until *time.Time) (*domain.User, error)) error {
+	type Request struct {
+		Reason string     `json:"reason"`
+		Until  *time.Time `json:"until"`
+	}
+
+	userID, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Invalid user ID"})
+	}
+
+	principal, ok := middleware.GetPrincipal(c)
+	if !ok {
+		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{"error": "Unauthorized"})
+	}
+
+	var req Request
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Invalid request"})
+	}
+
+	user, err := restrict(principal.UserID, userID, req.Reason, req.Until)
+	if err != nil {
+		if errors.Is(err, domain.ErrNotFound) {
+			return c.Status(fiber.StatusNotFound).JSON(fiber.Map{"error": "User not found"})
+		}
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": err.Error()})
+	}
+
+	return c.JSON(user)
+}
+
+func (h *AdminHandler) ReinstateUser(c *fiber.Ctx) error {
+	userID, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Invalid user ID"})
+	}
+
+	principal, ok := middleware.GetPrincipal(c)
+	if !ok {
+		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{"error": "Unauthorized"})
+	}
+
+	user, err := h.adminUsecase.Reinstate(principal.UserID, userID, adminReason(c))
+	if err != nil {
+		if errors.Is(err, domain.ErrNotFound) {
+			return c.Status(fiber.StatusNotFound).JSON(fiber.Map{"error": "User not found"})
+		}
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": err.Error()})
+	}
+
+	return c.JSON(user)
+}
+
+func (h *AdminHandler) ForcePasswordReset(c *fiber.Ctx) error {
+	userID, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Invalid user ID"})
+	}
+
+	principal, ok := middleware.GetPrincipal(c)
+	if !ok {
+		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{"error": "Unauthorized"})
+	}
+
+	if err := h.adminUsecase.ForcePasswordReset(principal.UserID, userID, adminReason(c)); err != nil {
+		if errors.Is(err, domain.ErrNotFound) {
+			return c.Status(fiber.StatusNotFound).JSON(fiber.Map{"error": "User not found"})
+		}
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": err.Error()})
+	}
+
+	return c.SendStatus(fiber.StatusNoContent)
+}
+
+func (h *AdminHandler) ListUserSessions(c *fiber.Ctx) error {
+	userID, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Invalid user ID"})
+	}
+
+	sessions, err := h.adminUsecase.ListUserSessions(userID)
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "Failed to fetch sessions"})
+	}
+
+	return c.JSON(sessions)
+}
+
+func (h *AdminHandler) RevokeUserSessions(c *fiber.Ctx) error {
+	userID, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Invalid user ID"})
+	}
+
+	principal, ok := middleware.GetPrincipal(c)
+	if !ok {
+		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{"error": "Unauthorized"})
+	}
+
+	if err := h.adminUsecase.RevokeUserSessions(principal.UserID, userID, adminReason(c)); err != nil {
+		if errors.Is(err, domain.ErrNotFound) {
+			return c.Status(fiber.StatusNotFound).JSON(fiber.Map{"error": "User not found"})
+		}
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "Failed to revoke sessions"})
+	}
+
+	return c.SendStatus(fiber.StatusNoContent)
+}
+
+func (h *AdminHandler) ListAuditLog(c *fiber.Ctx) error {
+	limit := c.QueryInt("limit", 100)
+	if limit <= 0 || limit > 500 {
+		limit = 100
+	}
+
+	filter := domain.AuditLogFilter{Action: c.Query("action"), Limit: limit}
+	if id, err := uuid.Parse(c.Query("admin_id")); err == nil {
+		filter.AdminID = &id
+	}
+	if id, err := uuid.Parse(c.Query("user_id")); err == nil {
+		filter.TargetUserID = &id
+	}
+
+	entries, err := h.adminUsecase.ListAuditLog(filter)
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "Failed to fetch audit log"})
+	}
+
+	return c.JSON(entries)
+}
+
+// adminReason reads the optional reason from an admin action's body.
+func adminReason(c *fiber.Ctx) string {
+	var req struct {
+		Reason string `json:"reason"`
+	}
+	if len(c.Body()) > 0 {
+		_ = c.BodyParser(&req)
+	}
+	return req.Reason
+}
@@ -1,8 +1,13 @@
 package http
 
 import (
+	"errors"
+	"math"
+	"strconv"
+
 	"github.com/gofiber/fiber/v2"
 	"github.com/pur108/talestoon-be/internal/domain"
+	"github.com/pur108/talestoon-be/internal/middleware"
 	"github.com/pur108/talestoon-be/internal/usecase"
 )
 
@@ -15,14 +20,20 @@ func NewAuthHandler(app *fiber.App, authUsecase usecase.AuthUsecase) {
 	group := app.Group("/api/auth")
 	group.Post("/signup", handler.SignUp)
 	group.Post("/login", handler.Login)
+	group.Post("/login/2fa", handler.VerifyTwoFactor)
+	group.Post("/refresh", handler.Refresh)
+	group.Post("/logout", middleware.Protected(authUsecase), middleware.SessionRequired(), handler.Logout)
+	group.Post("/password-reset/request", handler.RequestPasswordReset)
+	group.Post("/password-reset/confirm", handler.ConfirmPasswordReset)
+	group.Post("/verify", handler.VerifyEmail)
+	group.Post("/verify/resend", middleware.Protected(authUsecase), middleware.SessionRequired(), handler.ResendVerification)
 }
 
 func (h *AuthHandler) SignUp(c *fiber.Ctx) error {
 	type Request struct {
-		Username string          `json:"username"`
-		Email    string          `json:"email"`
-		Password string          `json:"password"`
-		Role     domain.UserRole `json:"role"`
+		Username string `json:"username"`
+		Email    string `json:"email"`
+		Password string `json:"password"`
 	}
 
 	var req Request
@@ -34,11 +45,7 @@ func (h *AuthHandler) SignUp(c *fiber.Ctx) error {
 		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Username, email, and password are required"})
 	}
 
-	if req.Role == "" {
-		req.Role = domain.RoleUser
-	}
-
-	user, err := h.authUsecase.SignUp(req.Username, req.Email, req.Password, req.Role)
+	user, err := h.authUsecase.SignUp(req.Username, req.Email, req.Password)
 	if err != nil {
 		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": err.Error()})
 	}
@@ -57,13 +64,208 @@ func (h *AuthHandler) Login(c *fiber.Ctx) error {
 		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Invalid request"})
 	}
 
-	token, user, err := h.authUsecase.Login(req.Identifier, req.Password)
+	result, err := h.authUsecase.Login(req.Identifier, req.Password, clientInfo(c))
+	if err != nil {
+		return authError(c, err)
+	}
+
+	return loginResultResponse(c, result)
+}
+
+func (h *AuthHandler) VerifyTwoFactor(c *fiber.Ctx) error {
+	type Request struct {
+		ChallengeToken string `json:"challenge_token"`
+		Code           string `json:"code"`
+	}
+
+	var req Request
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Invalid request"})
+	}
+
+	if req.ChallengeToken == "" || req.Code == "" {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Challenge token and code are required"})
+	}
+
+	tokens, user, err := h.authUsecase.VerifyTwoFactor(req.ChallengeToken, req.Code, clientInfo(c))
 	if err != nil {
-		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{"error": err.Error()})
+		return authError(c, err)
 	}
 
+	return loginResponse(c, tokens, user)
+}
+
+func clientInfo(c *fiber.Ctx) usecase.ClientInfo {
+	return usecase.ClientInfo{
+		IP:        c.IP(),
+		UserAgent: c.Get(fiber.HeaderUserAgent),
+	}
+}
+
+// authError maps login failures to a response, answering throttled requests
+// with 429 and a Retry-After header and suspended accounts with 403.
+func authError(c *fiber.Ctx, err error) error {
+	var throttled *domain.ThrottledError
+	if errors.As(err, &throttled) {
+		seconds := int(math.Ceil(throttled.RetryAfter.Seconds()))
+		c.Set(fiber.HeaderRetryAfter, strconv.Itoa(seconds))
+		return c.Status(fiber.StatusTooManyRequests).JSON(fiber.Map{"error": "Too many login attempts", "retry_after": seconds})
+	}
+	var suspended *domain.SuspendedError
+	if errors.As(err, &suspended) {
+		return c.Status(fiber.StatusForbidden).JSON(fiber.Map{
+			"error":  "Account " + string(suspended.Status),
+			"reason": suspended.Reason,
+			"until":  suspended.Until,
+		})
+	}
+	if errors.Is(err, domain.ErrPasswordResetRequired) {
+		return c.Status(fiber.StatusForbidden).JSON(fiber.Map{"error": "Password reset required. Check your email for a reset link."})
+	}
+	return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{"error": err.Error()})
+}
+
+// loginResultResponse answers with tokens, or with the challenge token when
+// the user still has to pass two-factor authentication.
+func loginResultResponse(c *fiber.Ctx, result *usecase.LoginResult) error {
+	if result.TwoFactorRequired {
+		return c.JSON(fiber.Map{
+			"two_factor_required": true,
+			"challenge_token":     result.ChallengeToken,
+		})
+	}
+
+	return loginResponse(c, result.Tokens, result.User)
+}
+
+func loginResponse(c *fiber.Ctx, tokens *usecase.TokenPair, user *domain.User) error {
 	return c.JSON(fiber.Map{
-		"token": token,
-		"user":  user,
+		"token":              tokens.AccessToken,
+		"refresh_token":      tokens.RefreshToken,
+		"expires_at":         tokens.ExpiresAt,
+		"refresh_expires_at": tokens.RefreshExpiresAt,
+		"user":               user,
 	})
 }
+
+func (h *AuthHandler) Refresh(c *fiber.Ctx) error {
+	type Request struct {
+		RefreshToken string `json:"refresh_token"`
+	}
+
+	var req Request
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Invalid request"})
+	}
+
+	if req.RefreshToken == "" {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Refresh token is required"})
+	}
+
+	tokens, err := h.authUsecase.Refresh(req.RefreshToken)
+	if err != nil {
+		var suspended *domain.SuspendedError
+		if errors.As(err, &suspended) {
+			return authError(c, err)
+		}
+		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{"error": "Invalid refresh token"})
+	}
+
+	return c.JSON(tokens)
+}
+
+func (h *AuthHandler) Logout(c *fiber.Ctx) error {
+	principal, ok := middleware.GetPrincipal(c)
+	if !ok {
+		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{"error": "Unauthorized"})
+	}
+
+	if err := h.authUsecase.Logout(principal.UserID, principal.SessionID, principal.TokenID); err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "Failed to log out"})
+	}
+
+	return c.SendStatus(fiber.StatusNoContent)
+}
+
+func (h *AuthHandler) RequestPasswordReset(c *fiber.Ctx) error {
+	type Request struct {
+		Email string `json:"email"`
+	}
+
+	var req Request
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Invalid request"})
+	}
+
+	if req.Email == "" {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Email is required"})
+	}
+
+	if err := h.authUsecase.RequestPasswordReset(req.Email); err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "Failed to send reset email"})
+	}
+
+	return c.Status(fiber.StatusAccepted).JSON(fiber.Map{"message": "If the email is registered, a reset link has been sent"})
+}
+
+func (h *AuthHandler) ConfirmPasswordReset(c *fiber.Ctx) error {
+	type Request struct {
+		Token    string `json:"token"`
+		Password string `json:"password"`
+	}
+
+	var req Request
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Invalid request"})
+	}
+
+	if req.Token == "" || len(req.Password) < 8 {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Token and a password of at least 8 characters are required"})
+	}
+
+	if err := h.authUsecase.ResetPassword(req.Token, req.Password); err != nil {
+		if errors.Is(err, domain.ErrInvalidToken) {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Invalid or expired reset token"})
+		}
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "Failed to reset password"})
+	}
+
+	return c.JSON(fiber.Map{"message": "Password has been reset"})
+}
+
+func (h *AuthHandler) VerifyEmail(c *fiber.Ctx) error {
+	type Request struct {
+		Token string `json:"token"`
+	}
+
+	var req Request
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Invalid request"})
+	}
+
+	if req.Token == "" {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Token is required"})
+	}
+
+	if err := h.authUsecase.VerifyEmail(req.Token); err != nil {
+		if errors.Is(err, domain.ErrInvalidToken) {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Invalid or expired verification token"})
+		}
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "Failed to verify email"})
+	}
+
+	return c.JSON(fiber.Map{"message": "Email verified"})
+}
+
+func (h *AuthHandler) ResendVerification(c *fiber.Ctx) error {
+	principal, ok := middleware.GetPrincipal(c)
+	if !ok {
+		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{"error": "Unauthorized"})
+	}
+
+	if err := h.authUsecase.SendVerificationEmail(principal.UserID); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": err.Error()})
+	}
+
+	return c.Status(fiber.StatusAccepted).JSON(fiber.Map{"message": "Verification email sent"})
+}
@@ -1,7 +1,8 @@
 package http
 
 import (
-	"fmt"
+	"errors"
+	"strconv"
 
 	"github.com/gofiber/fiber/v2"
 	"github.com/google/uuid"
@@ -14,19 +15,21 @@ type ComicHandler struct {
 	comicUsecase usecase.ComicUsecase
 }
 
-func NewComicHandler(app *fiber.App, comicUsecase usecase.ComicUsecase) {
+func NewComicHandler(app *fiber.App, comicUsecase usecase.ComicUsecase, authUsecase usecase.AuthUsecase) {
 	handler := &ComicHandler{comicUsecase}
 
-	app.Get("/api/comics", handler.ListComics)
-	app.Get("/api/comics/:id", handler.GetComic)
-	app.Get("/api/chapters/:id", handler.GetChapter)
-
-	creatorGroup := app.Group("/api/creator/comics", middleware.Protected(), middleware.RoleRequired(domain.RoleCreator, domain.RoleAdmin, domain.RoleUser))
-	creatorGroup.Post("", handler.CreateComic)
-	creatorGroup.Get("", handler.ListMyComics)
-	creatorGroup.Put("/:id", handler.UpdateComic)
-	creatorGroup.Delete("/:id", handler.DeleteComic)
-	creatorGroup.Post("/:id/chapters", handler.CreateChapter)
+	optionalAuth := middleware.OptionalAuth(authUsecase)
+	app.Get("/api/comics", optionalAuth, handler.ListComics)
+	app.Get("/api/comics/:id", optionalAuth, handler.GetComic)
+	app.Get("/api/chapters/:id", optionalAuth, handler.GetChapter)
+	app.Get("/api/creators/:username", optionalAuth, handler.GetCreatorPage)
+
+	creatorGroup := app.Group("/api/creator/comics", middleware.Protected(authUsecase), middleware.RoleRequired(domain.RoleCreator, domain.RoleAdmin), middleware.VerifiedRequired())
+	creatorGroup.Post("", middleware.ScopeRequired(domain.ScopeComicsWrite), handler.CreateComic)
+	creatorGroup.Get("", middleware.ScopeRequired(domain.ScopeComicsRead), handler.ListMyComics)
+	creatorGroup.Put("/:id", middleware.ScopeRequired(domain.ScopeComicsWrite), handler.UpdateComic)
+	creatorGroup.Delete("/:id", middleware.ScopeRequired(domain.ScopeComicsWrite), handler.DeleteComic)
+	creatorGroup.Post("/:id/chapters", middleware.ScopeRequired(domain.ScopeChaptersWrite), handler.CreateChapter)
 }
 
 func (h *ComicHandler) CreateChapter(c *fiber.Ctx) error {
@@ -36,10 +39,9 @@ func (h *ComicHandler) CreateChapter(c *fiber.Ctx) error {
 		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Invalid comic ID"})
 	}
 
-	userIDStr := c.Locals("user_id").(string)
-	userID, err := uuid.Parse(userIDStr)
-	if err != nil {
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Invalid user ID"})
+	principal, ok := middleware.GetPrincipal(c)
+	if !ok {
+		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{"error": "Unauthorized"})
 	}
 
 	var req usecase.CreateChapterInput
@@ -51,7 +53,7 @@ func (h *ComicHandler) CreateChapter(c *fiber.Ctx) error {
 		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Title, chapter number, and at least one image are required"})
 	}
 
-	chapter, err := h.comicUsecase.CreateChapter(comicID, userID, req)
+	chapter, err := h.comicUsecase.CreateChapter(comicID, principal.UserID, req)
 	if err != nil {
 		if err == domain.ErrUnauthorized {
 			return c.Status(fiber.StatusForbidden).JSON(fiber.Map{"error": "Unauthorized"})
@@ -68,12 +70,11 @@ func (h *ComicHandler) CreateComic(c *fiber.Ctx) error {
 		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Invalid request"})
 	}
 
-	userIDStr := c.Locals("user_id").(string)
-	userID, err := uuid.Parse(userIDStr)
-	if err != nil {
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Invalid user ID"})
+	principal, ok := middleware.GetPrincipal(c)
+	if !ok {
+		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{"error": "Unauthorized"})
 	}
-	req.CreatorID = userID
+	req.CreatorID = principal.UserID
 
 	if req.Title.En == "" {
 		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "English title is required"})
@@ -91,21 +92,15 @@ func (h *ComicHandler) CreateComic(c *fiber.Ctx) error {
 }
 
 func (h *ComicHandler) ListMyComics(c *fiber.Ctx) error {
-	fmt.Println("ListMyComics called")
-	userIDStr := c.Locals("user_id").(string)
-	fmt.Printf("UserID from locals: %s\n", userIDStr)
-	userID, err := uuid.Parse(userIDStr)
-	if err != nil {
-		fmt.Printf("Error parsing UUID: %v\n", err)
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Invalid user ID"})
+	principal, ok := middleware.GetPrincipal(c)
+	if !ok {
+		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{"error": "Unauthorized"})
 	}
 
-	comics, err := h.comicUsecase.ListMyComics(userID)
+	comics, err := h.comicUsecase.ListMyComics(principal.UserID)
 	if err != nil {
-		fmt.Printf("Error fetching comics: %v\n", err)
 		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "Failed to fetch comics"})
 	}
-	fmt.Printf("Found %d comics for user\n", len(comics))
 
 	return c.JSON(comics)
 }
@@ -117,10 +112,9 @@ func (h *ComicHandler) UpdateComic(c *fiber.Ctx) error {
 		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Invalid comic ID"})
 	}
 
-	userIDStr := c.Locals("user_id").(string)
-	userID, err := uuid.Parse(userIDStr)
-	if err != nil {
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Invalid user ID"})
+	principal, ok := middleware.GetPrincipal(c)
+	if !ok {
+		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{"error": "Unauthorized"})
 	}
 
 	var req usecase.UpdateComicInput
@@ -128,7 +122,7 @@ func (h *ComicHandler) UpdateComic(c *fiber.Ctx) error {
 		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Invalid request"})
 	}
 
-	comic, err := h.comicUsecase.UpdateComic(id, userID, req)
+	comic, err := h.comicUsecase.UpdateComic(id, principal.UserID, req)
 	if err != nil {
 		if err == domain.ErrUnauthorized {
 			return c.Status(fiber.StatusForbidden).JSON(fiber.Map{"error": "Unauthorized"})
@@ -146,13 +140,12 @@ func (h *ComicHandler) DeleteComic(c *fiber.Ctx) error {
 		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Invalid comic ID"})
 	}
 
-	userIDStr := c.Locals("user_id").(string)
-	userID, err := uuid.Parse(userIDStr)
-	if err != nil {
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Invalid user ID"})
+	principal, ok := middleware.GetPrincipal(c)
+	if !ok {
+		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{"error": "Unauthorized"})
 	}
 
-	err = h.comicUsecase.DeleteComic(id, userID)
+	err = h.comicUsecase.DeleteComic(id, principal.UserID)
 	if err != nil {
 		if err == domain.ErrUnauthorized {
 			return c.Status(fiber.StatusForbidden).JSON(fiber.Map{"error": "Unauthorized"})
@@ -170,8 +163,12 @@ func (h *ComicHandler) GetComic(c *fiber.Ctx) error {
 		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Invalid comic ID"})
 	}
 
-	comic, err := h.comicUsecase.GetComic(id)
+	viewer, _ := middleware.GetPrincipal(c)
+	comic, err := h.comicUsecase.GetComic(id, viewer)
 	if err != nil {
+		if errors.Is(err, usecase.ErrNSFWHidden) {
+			return c.Status(fiber.StatusForbidden).JSON(fiber.Map{"error": err.Error(), "nsfw": true})
+		}
 		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{"error": "Comic not found"})
 	}
 
@@ -185,19 +182,84 @@ func (h *ComicHandler) GetChapter(c *fiber.Ctx) error {
 		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Invalid chapter ID"})
 	}
 
-	chapter, err := h.comicUsecase.GetChapter(id)
+	viewer, _ := middleware.GetPrincipal(c)
+	chapter, err := h.comicUsecase.GetChapter(id, viewer)
 	if err != nil {
+		if errors.Is(err, usecase.ErrAgeRestricted) {
+			return c.Status(fiber.StatusForbidden).JSON(fiber.Map{"error": err.Error(), "age_restricted": true})
+		}
+		if errors.Is(err, usecase.ErrNSFWHidden) {
+			return c.Status(fiber.StatusForbidden).JSON(fiber.Map{"error": err.Error(), "nsfw": true})
+		}
 		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{"error": "Chapter not found"})
 	}
 
 	return c.JSON(chapter)
 }
 
+// ListComics returns a page of the public catalog. Query parameters: genre,
+// tag (slug), status, nsfw, author, creator (username), sort (updated,
+// created, title, popularity), cursor and limit.
 func (h *ComicHandler) ListComics(c *fiber.Ctx) error {
-	comics, err := h.comicUsecase.ListComics()
+	input, err := listComicsInput(c)
 	if err != nil {
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "Failed to fetch comics"})
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": err.Error()})
 	}
 
-	return c.JSON(comics)
+	viewer, _ := middleware.GetPrincipal(c)
+	page, err := h.comicUsecase.ListComics(viewer, input)
+	if err != nil {
+		return listComicsError(c, err)
+	}
+
+	return c.JSON(page)
+}
+
+// listComicsInput reads the catalog filters, sort and paging from the query
+// string.
+func listComicsInput(c *fiber.Ctx) (usecase.ListComicsInput, error) {
+	input := usecase.ListComicsInput{
+		Genre:   c.Query("genre"),
+		Tag:     c.Query("tag"),
+		Status:  domain.ComicStatus(c.Query("status")),
+		Author:  c.Query("author"),
+		Creator: c.Query("creator"),
+		Sort:    domain.ComicSort(c.Query("sort")),
+		Cursor:  c.Query("cursor"),
+		Limit:   c.QueryInt("limit"),
+	}
+	if nsfw := c.Query("nsfw"); nsfw != "" {
+		value, err := strconv.ParseBool(nsfw)
+		if err != nil {
+			return input, errors.New("nsfw must be true or false")
+		}
+		input.NSFW = &value
+	}
+	return input, nil
+}
+
+func listComicsError(c *fiber.Ctx, err error) error {
+	if errors.Is(err, usecase.ErrNSFWHidden) {
+		return c.Status(fiber.StatusForbidden).JSON(fiber.Map{"error": err.Error(), "nsfw": true})
+	}
+	if errors.Is(err, domain.ErrInvalidCursor) {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Invalid cursor"})
+	}
+	if errors.Is(err, usecase.ErrInvalidListQuery) {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": err.Error()})
+	}
+	return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "Failed to fetch comics"})
+}
+
+func (h *ComicHandler) GetCreatorPage(c *fiber.Ctx) error {
+	viewer, _ := middleware.GetPrincipal(c)
+	page, err := h.comicUsecase.GetCreatorPage(c.Params("username"), viewer)
+	if err != nil {
+		if errors.Is(err, domain.ErrNotFound) {
+			return c.Status(fiber.StatusNotFound).JSON(fiber.Map{"error": "Creator not found"})
+		}
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "Failed to fetch creator"})
+	}
+
+	return c.JSON(page)
 }
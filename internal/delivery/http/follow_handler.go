@@ -0,0 +1,120 @@
+package http
+
+import (
+	"errors"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+	"github.com/pur108/talestoon-be/internal/domain"
+	"github.com/pur108/talestoon-be/internal/middleware"
+	"github.com/pur108/talestoon-be/internal/usecase"
+)
+
+type FollowHandler struct {
+	followUsecase usecase.FollowUsecase
+}
+
+func NewFollowHandler(app *fiber.App, followUsecase usecase.FollowUsecase, authUsecase usecase.AuthUsecase) {
+	handler := &FollowHandler{followUsecase}
+	protected := middleware.Protected(authUsecase)
+	session := middleware.SessionRequired()
+
+	app.Post("/api/creators/:username/follow", protected, session, handler.FollowCreator)
+	app.Delete("/api/creators/:username/follow", protected, session, handler.UnfollowCreator)
+	app.Post("/api/comics/:id/subscribe", protected, session, handler.SubscribeComic)
+	app.Delete("/api/comics/:id/subscribe", protected, session, handler.UnsubscribeComic)
+	app.Get("/api/users/me/following", protected, session, handler.ListFollowing)
+}
+
+func (h *FollowHandler) FollowCreator(c *fiber.Ctx) error {
+	principal, ok := middleware.GetPrincipal(c)
+	if !ok {
+		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{"error": "Unauthorized"})
+	}
+
+	status, err := h.followUsecase.FollowCreator(principal.UserID, c.Params("username"))
+	if err != nil {
+		return followError(c, err)
+	}
+
+	return c.JSON(status)
+}
+
+func (h *FollowHandler) UnfollowCreator(c *fiber.Ctx) error {
+	principal, ok := middleware.GetPrincipal(c)
+	if !ok {
+		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{"error": "Unauthorized"})
+	}
+
+	status, err := h.followUsecase.UnfollowCreator(principal.UserID, c.Params("username"))
+	if err != nil {
+		return followError(c, err)
+	}
+
+	return c.JSON(status)
+}
+
+func (h *FollowHandler) SubscribeComic(c *fiber.Ctx) error {
+	id, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Invalid comic ID"})
+	}
+
+	principal, ok := middleware.GetPrincipal(c)
+	if !ok {
+		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{"error": "Unauthorized"})
+	}
+
+	status, err := h.followUsecase.SubscribeComic(principal, id)
+	if err != nil {
+		return followError(c, err)
+	}
+
+	return c.JSON(status)
+}
+
+func (h *FollowHandler) UnsubscribeComic(c *fiber.Ctx) error {
+	id, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Invalid comic ID"})
+	}
+
+	principal, ok := middleware.GetPrincipal(c)
+	if !ok {
+		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{"error": "Unauthorized"})
+	}
+
+	status, err := h.followUsecase.UnsubscribeComic(principal, id)
+	if err != nil {
+		return followError(c, err)
+	}
+
+	return c.JSON(status)
+}
+
+func (h *FollowHandler) ListFollowing(c *fiber.Ctx) error {
+	principal, ok := middleware.GetPrincipal(c)
+	if !ok {
+		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{"error": "Unauthorized"})
+	}
+
+	following, err := h.followUsecase.ListFollowing(principal.UserID)
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "Failed to fetch followed creators and comics"})
+	}
+
+	return c.JSON(following)
+}
+
+func followError(c *fiber.Ctx, err error) error {
+	switch {
+	case errors.Is(err, domain.ErrNotFound):
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{"error": "Not found"})
+	case errors.Is(err, usecase.ErrFollowSelf):
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": err.Error()})
+	case errors.Is(err, usecase.ErrAgeRestricted):
+		return c.Status(fiber.StatusForbidden).JSON(fiber.Map{"error": err.Error(), "age_restricted": true})
+	default:
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "Failed to update follow"})
+	}
+}
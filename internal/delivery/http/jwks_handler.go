@@ -0,0 +1,22 @@
+package http
+
+import (
+	"github.com/gofiber/fiber/v2"
+	"github.com/pur108/talestoon-be/internal/keyring"
+)
+
+type JWKSHandler struct {
+	keys *keyring.KeyRing
+}
+
+func NewJWKSHandler(app *fiber.App, keys *keyring.KeyRing) {
+	handler := &JWKSHandler{keys}
+	app.Get("/.well-known/jwks.json", handler.GetJWKS)
+}
+
+// GetJWKS publishes the public verification keys so other services can
+// validate our access tokens without sharing a secret.
+func (h *JWKSHandler) GetJWKS(c *fiber.Ctx) error {
+	c.Set(fiber.HeaderCacheControl, "public, max-age=300")
+	return c.JSON(h.keys.JWKS())
+}
@@ -0,0 +1,138 @@
+package http
+
+import (
+	"errors"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+	"github.com/pur108/talestoon-be/internal/domain"
+	"github.com/pur108/talestoon-be/internal/middleware"
+	"github.com/pur108/talestoon-be/internal/usecase"
+)
+
+type NotificationHandler struct {
+	notificationUsecase usecase.NotificationUsecase
+}
+
+func NewNotificationHandler(app *fiber.App, notificationUsecase usecase.NotificationUsecase, authUsecase usecase.AuthUsecase) {
+	handler := &NotificationHandler{notificationUsecase}
+	group := app.Group("/api/notifications", middleware.Protected(authUsecase), middleware.SessionRequired())
+	group.Get("", handler.List)
+	group.Get("/unread-count", handler.UnreadCount)
+	group.Post("/read", handler.MarkRead)
+	group.Post("/read-all", handler.MarkAllRead)
+	group.Get("/preferences", handler.GetPreferences)
+	group.Put("/preferences", handler.UpdatePreferences)
+}
+
+// List returns the caller's notifications, newest first. Pass the previous
+// page's next_cursor as ?cursor= to page back; ?unread=true limits the list to
+// unread ones.
+func (h *NotificationHandler) List(c *fiber.Ctx) error {
+	principal, ok := middleware.GetPrincipal(c)
+	if !ok {
+		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{"error": "Unauthorized"})
+	}
+
+	filter := domain.NotificationFilter{
+		UnreadOnly: c.QueryBool("unread"),
+		Cursor:     c.Query("cursor"),
+		Limit:      c.QueryInt("limit"),
+	}
+
+	page, err := h.notificationUsecase.List(principal.UserID, filter)
+	if err != nil {
+		if errors.Is(err, domain.ErrInvalidCursor) {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Invalid cursor"})
+		}
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "Failed to fetch notifications"})
+	}
+
+	return c.JSON(page)
+}
+
+func (h *NotificationHandler) UnreadCount(c *fiber.Ctx) error {
+	principal, ok := middleware.GetPrincipal(c)
+	if !ok {
+		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{"error": "Unauthorized"})
+	}
+
+	count, err := h.notificationUsecase.UnreadCount(principal.UserID)
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "Failed to count notifications"})
+	}
+
+	return c.JSON(fiber.Map{"unread_count": count})
+}
+
+func (h *NotificationHandler) MarkRead(c *fiber.Ctx) error {
+	type Request struct {
+		IDs []uuid.UUID `json:"ids"`
+	}
+
+	principal, ok := middleware.GetPrincipal(c)
+	if !ok {
+		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{"error": "Unauthorized"})
+	}
+
+	var req Request
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Invalid request"})
+	}
+
+	updated, err := h.notificationUsecase.MarkRead(principal.UserID, req.IDs)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": err.Error()})
+	}
+
+	return c.JSON(fiber.Map{"updated": updated})
+}
+
+func (h *NotificationHandler) MarkAllRead(c *fiber.Ctx) error {
+	principal, ok := middleware.GetPrincipal(c)
+	if !ok {
+		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{"error": "Unauthorized"})
+	}
+
+	updated, err := h.notificationUsecase.MarkAllRead(principal.UserID)
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "Failed to update notifications"})
+	}
+
+	return c.JSON(fiber.Map{"updated": updated})
+}
+
+func (h *NotificationHandler) GetPreferences(c *fiber.Ctx) error {
+	principal, ok := middleware.GetPrincipal(c)
+	if !ok {
+		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{"error": "Unauthorized"})
+	}
+
+	preferences, err := h.notificationUsecase.GetPreferences(principal.UserID)
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "Failed to fetch notification preferences"})
+	}
+
+	return c.JSON(preferences)
+}
+
+// UpdatePreferences switches notification types on or off, e.g.
+// {"new_follower": false}. Types left out are unchanged.
+func (h *NotificationHandler) UpdatePreferences(c *fiber.Ctx) error {
+	principal, ok := middleware.GetPrincipal(c)
+	if !ok {
+		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{"error": "Unauthorized"})
+	}
+
+	var req map[domain.NotificationType]bool
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Invalid request"})
+	}
+
+	preferences, err := h.notificationUsecase.UpdatePreferences(principal.UserID, req)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": err.Error()})
+	}
+
+	return c.JSON(preferences)
+}
@@ -0,0 +1,130 @@
+package http
+
+import (
+	"errors"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+	"github.com/pur108/talestoon-be/internal/domain"
+	"github.com/pur108/talestoon-be/internal/middleware"
+	"github.com/pur108/talestoon-be/internal/usecase"
+)
+
+type OIDCHandler struct {
+	oidcUsecase usecase.OIDCUsecase
+}
+
+func NewOIDCHandler(app *fiber.App, oidcUsecase usecase.OIDCUsecase, authUsecase usecase.AuthUsecase) {
+	handler := &OIDCHandler{oidcUsecase}
+	group := app.Group("/api/auth/oidc")
+	group.Get("/providers", handler.ListProviders)
+	group.Get("/identities", middleware.Protected(authUsecase), middleware.SessionRequired(), handler.ListIdentities)
+	group.Delete("/identities/:id", middleware.Protected(authUsecase), middleware.SessionRequired(), handler.UnlinkIdentity)
+	group.Get("/:provider/authorize", middleware.OptionalAuth(authUsecase), handler.Authorize)
+	group.Post("/:provider/callback", handler.Callback)
+}
+
+func (h *OIDCHandler) ListProviders(c *fiber.Ctx) error {
+	return c.JSON(h.oidcUsecase.Providers())
+}
+
+// Authorize returns the provider URL to send the browser to. Signed-in users
+// pass link=true to attach the provider to their current account.
+func (h *OIDCHandler) Authorize(c *fiber.Ctx) error {
+	var linkUserID *uuid.UUID
+	if c.QueryBool("link") {
+		principal, ok := middleware.GetPrincipal(c)
+		if !ok || principal.IsAPIKey() {
+			return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{"error": "Log in to link an identity provider"})
+		}
+		linkUserID = &principal.UserID
+	}
+
+	authURL, err := h.oidcUsecase.BeginLogin(c.Params("provider"), linkUserID)
+	if err != nil {
+		if errors.Is(err, usecase.ErrUnknownProvider) {
+			return c.Status(fiber.StatusNotFound).JSON(fiber.Map{"error": err.Error()})
+		}
+		return c.Status(fiber.StatusBadGateway).JSON(fiber.Map{"error": "Identity provider is unavailable"})
+	}
+
+	return c.JSON(fiber.Map{"authorization_url": authURL})
+}
+
+// Callback completes the flow with the code and state the provider appended
+// to the redirect URL.
+func (h *OIDCHandler) Callback(c *fiber.Ctx) error {
+	type Request struct {
+		Code  string `json:"code"`
+		State string `json:"state"`
+	}
+
+	var req Request
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Invalid request"})
+	}
+
+	if req.Code == "" || req.State == "" {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Code and state are required"})
+	}
+
+	result, err := h.oidcUsecase.CompleteLogin(c.Params("provider"), req.State, req.Code, clientInfo(c))
+	if err != nil {
+		var suspended *domain.SuspendedError
+		switch {
+		case errors.As(err, &suspended):
+			return authError(c, err)
+		case errors.Is(err, usecase.ErrUnknownProvider):
+			return c.Status(fiber.StatusNotFound).JSON(fiber.Map{"error": err.Error()})
+		case errors.Is(err, usecase.ErrIdentityLinked), errors.Is(err, usecase.ErrOIDCAccountExists):
+			return c.Status(fiber.StatusConflict).JSON(fiber.Map{"error": err.Error()})
+		case errors.Is(err, usecase.ErrOIDCEmailRequired):
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": err.Error()})
+		}
+		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{"error": "Login with identity provider failed"})
+	}
+
+	if result.Linked != nil {
+		return c.JSON(result.Linked)
+	}
+
+	return loginResultResponse(c, result.Login)
+}
+
+func (h *OIDCHandler) ListIdentities(c *fiber.Ctx) error {
+	principal, ok := middleware.GetPrincipal(c)
+	if !ok {
+		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{"error": "Unauthorized"})
+	}
+
+	identities, err := h.oidcUsecase.ListIdentities(principal.UserID)
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "Failed to fetch linked identities"})
+	}
+
+	return c.JSON(identities)
+}
+
+func (h *OIDCHandler) UnlinkIdentity(c *fiber.Ctx) error {
+	id, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Invalid identity ID"})
+	}
+
+	principal, ok := middleware.GetPrincipal(c)
+	if !ok {
+		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{"error": "Unauthorized"})
+	}
+
+	if err := h.oidcUsecase.UnlinkIdentity(principal.UserID, id); err != nil {
+		switch {
+		case errors.Is(err, domain.ErrNotFound):
+			return c.Status(fiber.StatusNotFound).JSON(fiber.Map{"error": "Identity not found"})
+		case errors.Is(err, usecase.ErrLastLoginMethod):
+			return c.Status(fiber.StatusConflict).JSON(fiber.Map{"error": err.Error()})
+		}
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "Failed to unlink identity"})
+	}
+
+	return c.SendStatus(fiber.StatusNoContent)
+}
@@ -0,0 +1,57 @@
+package http
+
+import (
+	"errors"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/pur108/talestoon-be/internal/middleware"
+	"github.com/pur108/talestoon-be/internal/usecase"
+)
+
+type SearchHandler struct {
+	searchUsecase usecase.SearchUsecase
+}
+
+func NewSearchHandler(app *fiber.App, searchUsecase usecase.SearchUsecase, authUsecase usecase.AuthUsecase) {
+	handler := &SearchHandler{searchUsecase}
+	optionalAuth := middleware.OptionalAuth(authUsecase)
+	app.Get("/api/search", optionalAuth, handler.Search)
+	app.Get("/api/search/suggest", optionalAuth, handler.Suggest)
+}
+
+// Search returns comics matching ?q=, most relevant first, paged with page
+// and page_size.
+func (h *SearchHandler) Search(c *fiber.Ctx) error {
+	viewer, _ := middleware.GetPrincipal(c)
+	page, err := h.searchUsecase.Search(viewer, c.Query("q"), c.QueryInt("page", 1), c.QueryInt("page_size"))
+	if err != nil {
+		if errors.Is(err, usecase.ErrInvalidSearchQuery) {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": err.Error()})
+		}
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "Search failed"})
+	}
+
+	return c.JSON(page)
+}
+
+// Suggest returns typeahead suggestions for ?q=, up to ?limit= of each kind.
+// Anonymous responses are shared through caches; signed-in ones depend on
+// the reader's preferences and are only cached by the browser.
+func (h *SearchHandler) Suggest(c *fiber.Ctx) error {
+	viewer, _ := middleware.GetPrincipal(c)
+	suggestions, err := h.searchUsecase.Suggest(viewer, c.Query("q"), c.QueryInt("limit"))
+	if err != nil {
+		if errors.Is(err, usecase.ErrInvalidSuggestQuery) {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": err.Error()})
+		}
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "Failed to fetch suggestions"})
+	}
+
+	c.Set(fiber.HeaderVary, "Authorization, X-API-Key")
+	if viewer == nil {
+		c.Set(fiber.HeaderCacheControl, "public, max-age=60")
+	} else {
+		c.Set(fiber.HeaderCacheControl, "private, max-age=30")
+	}
+	return c.JSON(suggestions)
+}
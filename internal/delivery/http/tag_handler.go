@@ -0,0 +1,211 @@
+package http
+
+import (
+	"errors"
+	"net/url"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+	"github.com/pur108/talestoon-be/internal/domain"
+	"github.com/pur108/talestoon-be/internal/middleware"
+	"github.com/pur108/talestoon-be/internal/usecase"
+)
+
+type TagHandler struct {
+	tagUsecase usecase.TagUsecase
+}
+
+func NewTagHandler(app *fiber.App, tagUsecase usecase.TagUsecase, authUsecase usecase.AuthUsecase) {
+	handler := &TagHandler{tagUsecase}
+	app.Get("/api/tags", handler.ListTags)
+	app.Get("/api/tags/:slug", handler.GetTag)
+	app.Get("/api/tags/:slug/comics", middleware.OptionalAuth(authUsecase), handler.ListComics)
+
+	admin := app.Group("/api/admin/tags", middleware.Protected(authUsecase), middleware.SessionRequired(), middleware.RoleRequired(domain.RoleAdmin))
+	admin.Get("", handler.ListAllTags)
+	admin.Put("/:id", handler.RenameTag)
+	admin.Put("/:id/translations/:language", handler.SetTranslation)
+	admin.Post("/:id/merge", handler.MergeTags)
+	admin.Delete("/:id", handler.DeleteTag)
+}
+
+// ListTags lists the tags used by public comics, most used first, or by slug
+// with sort=name.
+func (h *TagHandler) ListTags(c *fiber.Ctx) error {
+	return h.listTags(c, true)
+}
+
+// ListAllTags also lists tags no public comic uses, so admins can find
+// duplicates and leftovers.
+func (h *TagHandler) ListAllTags(c *fiber.Ctx) error {
+	return h.listTags(c, false)
+}
+
+func (h *TagHandler) listTags(c *fiber.Ctx, inUse bool) error {
+	page := c.QueryInt("page", 1)
+	if page < 1 {
+		page = 1
+	}
+	pageSize := c.QueryInt("page_size", 50)
+	if pageSize <= 0 || pageSize > 100 {
+		pageSize = 50
+	}
+
+	tags, total, err := h.tagUsecase.ListTags(domain.TagFilter{
+		Query:  c.Query("q"),
+		InUse:  inUse,
+		Sort:   domain.TagSort(c.Query("sort")),
+		Limit:  pageSize,
+		Offset: (page - 1) * pageSize,
+	})
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "Failed to fetch tags"})
+	}
+
+	return c.JSON(fiber.Map{
+		"data":      tags,
+		"total":     total,
+		"page":      page,
+		"page_size": pageSize,
+	})
+}
+
+func (h *TagHandler) GetTag(c *fiber.Ctx) error {
+	tag, err := h.tagUsecase.GetTag(tagSlug(c))
+	if err != nil {
+		if errors.Is(err, domain.ErrNotFound) {
+			return c.Status(fiber.StatusNotFound).JSON(fiber.Map{"error": "Tag not found"})
+		}
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "Failed to fetch tag"})
+	}
+
+	return c.JSON(tag)
+}
+
+// ListComics takes the same query parameters as /api/comics, apart from tag.
+func (h *TagHandler) ListComics(c *fiber.Ctx) error {
+	input, err := listComicsInput(c)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": err.Error()})
+	}
+
+	viewer, _ := middleware.GetPrincipal(c)
+	page, err := h.tagUsecase.ListComics(viewer, tagSlug(c), input)
+	if err != nil {
+		if errors.Is(err, domain.ErrNotFound) {
+			return c.Status(fiber.StatusNotFound).JSON(fiber.Map{"error": "Tag not found"})
+		}
+		return listComicsError(c, err)
+	}
+
+	return c.JSON(page)
+}
+
+func (h *TagHandler) RenameTag(c *fiber.Ctx) error {
+	type Request struct {
+		Slug string `json:"slug"`
+	}
+
+	id, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Invalid tag ID"})
+	}
+
+	var req Request
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Invalid request"})
+	}
+
+	tag, err := h.tagUsecase.RenameTag(id, req.Slug)
+	if err != nil {
+		return tagError(c, err)
+	}
+
+	return c.JSON(tag)
+}
+
+func (h *TagHandler) SetTranslation(c *fiber.Ctx) error {
+	type Request struct {
+		Name string `json:"name"`
+	}
+
+	id, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Invalid tag ID"})
+	}
+
+	var req Request
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Invalid request"})
+	}
+
+	tag, err := h.tagUsecase.SetTranslation(id, c.Params("language"), req.Name)
+	if err != nil {
+		return tagError(c, err)
+	}
+
+	return c.JSON(tag)
+}
+
+// MergeTags folds the tag in the path into the tag given as "into" and
+// returns the merged tag.
+func (h *TagHandler) MergeTags(c *fiber.Ctx) error {
+	type Request struct {
+		Into uuid.UUID `json:"into"`
+	}
+
+	id, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Invalid tag ID"})
+	}
+
+	var req Request
+	if err := c.BodyParser(&req); err != nil || req.Into == uuid.Nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "into must be a tag ID"})
+	}
+
+	tag, err := h.tagUsecase.MergeTags(id, req.Into)
+	if err != nil {
+		return tagError(c, err)
+	}
+
+	return c.JSON(tag)
+}
+
+func (h *TagHandler) DeleteTag(c *fiber.Ctx) error {
+	id, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Invalid tag ID"})
+	}
+
+	if err := h.tagUsecase.DeleteTag(id); err != nil {
+		return tagError(c, err)
+	}
+
+	return c.SendStatus(fiber.StatusNoContent)
+}
+
+// tagSlug returns the decoded :slug parameter; slugs may be in Thai.
+func tagSlug(c *fiber.Ctx) string {
+	slug, err := url.PathUnescape(c.Params("slug"))
+	if err != nil {
+		return c.Params("slug")
+	}
+	return slug
+}
+
+func tagError(c *fiber.Ctx, err error) error {
+	switch {
+	case errors.Is(err, domain.ErrNotFound):
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{"error": "Tag not found"})
+	case errors.Is(err, usecase.ErrTagSlugTaken):
+		return c.Status(fiber.StatusConflict).JSON(fiber.Map{"error": err.Error()})
+	case errors.Is(err, usecase.ErrInvalidTagSlug),
+		errors.Is(err, usecase.ErrInvalidTagLanguage),
+		errors.Is(err, usecase.ErrInvalidTagName),
+		errors.Is(err, usecase.ErrMergeTagIntoItself):
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": err.Error()})
+	default:
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "Failed to update tag"})
+	}
+}
@@ -0,0 +1,109 @@
+package http
+
+import (
+	"errors"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/pur108/talestoon-be/internal/domain"
+	"github.com/pur108/talestoon-be/internal/middleware"
+	"github.com/pur108/talestoon-be/internal/usecase"
+)
+
+type TwoFactorHandler struct {
+	twoFactorUsecase usecase.TwoFactorUsecase
+}
+
+func NewTwoFactorHandler(app *fiber.App, twoFactorUsecase usecase.TwoFactorUsecase, authUsecase usecase.AuthUsecase) {
+	handler := &TwoFactorHandler{twoFactorUsecase}
+	group := app.Group("/api/auth/2fa", middleware.Protected(authUsecase), middleware.SessionRequired(), middleware.RoleRequired(domain.RoleCreator, domain.RoleAdmin))
+	group.Post("/setup", handler.Setup)
+	group.Post("/enable", handler.Enable)
+	group.Post("/disable", handler.Disable)
+	group.Post("/recovery-codes", handler.RegenerateRecoveryCodes)
+}
+
+func (h *TwoFactorHandler) Setup(c *fiber.Ctx) error {
+	principal, ok := middleware.GetPrincipal(c)
+	if !ok {
+		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{"error": "Unauthorized"})
+	}
+
+	setup, err := h.twoFactorUsecase.Setup(principal.UserID)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": err.Error()})
+	}
+
+	return c.JSON(setup)
+}
+
+func (h *TwoFactorHandler) Enable(c *fiber.Ctx) error {
+	type Request struct {
+		Code string `json:"code"`
+	}
+
+	principal, ok := middleware.GetPrincipal(c)
+	if !ok {
+		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{"error": "Unauthorized"})
+	}
+
+	var req Request
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Invalid request"})
+	}
+
+	codes, err := h.twoFactorUsecase.Enable(principal.UserID, req.Code)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": err.Error()})
+	}
+
+	return c.JSON(fiber.Map{"recovery_codes": codes})
+}
+
+func (h *TwoFactorHandler) Disable(c *fiber.Ctx) error {
+	type Request struct {
+		Password string `json:"password"`
+		Code     string `json:"code"`
+	}
+
+	principal, ok := middleware.GetPrincipal(c)
+	if !ok {
+		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{"error": "Unauthorized"})
+	}
+
+	var req Request
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Invalid request"})
+	}
+
+	if err := h.twoFactorUsecase.Disable(principal.UserID, req.Password, req.Code); err != nil {
+		if errors.Is(err, domain.ErrInvalidCredentials) || errors.Is(err, usecase.ErrInvalidTwoFactorCode) {
+			return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{"error": err.Error()})
+		}
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": err.Error()})
+	}
+
+	return c.SendStatus(fiber.StatusNoContent)
+}
+
+func (h *TwoFactorHandler) RegenerateRecoveryCodes(c *fiber.Ctx) error {
+	type Request struct {
+		Code string `json:"code"`
+	}
+
+	principal, ok := middleware.GetPrincipal(c)
+	if !ok {
+		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{"error": "Unauthorized"})
+	}
+
+	var req Request
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Invalid request"})
+	}
+
+	codes, err := h.twoFactorUsecase.RegenerateRecoveryCodes(principal.UserID, req.Code)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": err.Error()})
+	}
+
+	return c.JSON(fiber.Map{"recovery_codes": codes})
+}
@@ -11,15 +11,17 @@ import (
 
 	"github.com/gofiber/fiber/v2"
 	"github.com/google/uuid"
+	"github.com/pur108/talestoon-be/internal/domain"
 	"github.com/pur108/talestoon-be/internal/middleware"
+	"github.com/pur108/talestoon-be/internal/usecase"
 )
 
 type UploadHandler struct{}
 
-func NewUploadHandler(app *fiber.App) {
+func NewUploadHandler(app *fiber.App, authUsecase usecase.AuthUsecase) {
 	handler := &UploadHandler{}
 
-	app.Post("/api/upload", middleware.Protected(), handler.UploadFile)
+	app.Post("/api/upload", middleware.Protected(authUsecase), middleware.ScopeRequired(domain.ScopeUploadsWrite), handler.UploadFile)
 }
 
 func (h *UploadHandler) UploadFile(c *fiber.Ctx) error {
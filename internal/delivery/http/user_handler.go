@@ -1,31 +1,50 @@
 package http
 
 import (
+	"errors"
+	"time"
+
 	"github.com/gofiber/fiber/v2"
 	"github.com/google/uuid"
+	"github.com/pur108/talestoon-be/internal/domain"
 	"github.com/pur108/talestoon-be/internal/middleware"
 	"github.com/pur108/talestoon-be/internal/usecase"
 )
 
 type UserHandler struct {
-	userUsecase usecase.UserUsecase
+	userUsecase    usecase.UserUsecase
+	roleUsecase    usecase.RoleUsecase
+	sessionUsecase usecase.SessionUsecase
 }
 
-func NewUserHandler(app *fiber.App, userUsecase usecase.UserUsecase) {
-	handler := &UserHandler{userUsecase}
-	group := app.Group("/api/users", middleware.Protected())
-	group.Get("/me", handler.GetProfile)
-	group.Post("/become-creator", handler.BecomeCreator)
+func NewUserHandler(app *fiber.App, userUsecase usecase.UserUsecase, roleUsecase usecase.RoleUsecase, sessionUsecase usecase.SessionUsecase, authUsecase usecase.AuthUsecase) {
+	handler := &UserHandler{userUsecase, roleUsecase, sessionUsecase}
+	protected := middleware.Protected(authUsecase)
+	session := middleware.SessionRequired()
+
+	// Middleware is attached per route: a group on /api/users/me would also
+	// match public profiles of usernames starting with "me".
+	group := app.Group("/api/users")
+	group.Get("/me", protected, session, handler.GetProfile)
+	group.Patch("/me", protected, session, handler.UpdateProfile)
+	group.Get("/me/preferences", protected, session, handler.GetPreferences)
+	group.Put("/me/preferences", protected, session, handler.UpdatePreferences)
+	group.Post("/me/age-confirmation", protected, session, handler.ConfirmAge)
+	group.Get("/me/role-requests", protected, session, handler.ListMyRoleRequests)
+	group.Get("/me/sessions", protected, session, handler.ListSessions)
+	group.Delete("/me/sessions", protected, session, handler.RevokeOtherSessions)
+	group.Delete("/me/sessions/:id", protected, session, handler.RevokeSession)
+	group.Post("/become-creator", protected, session, handler.BecomeCreator)
+	group.Get("/:username", handler.GetPublicProfile)
 }
 
 func (h *UserHandler) GetProfile(c *fiber.Ctx) error {
-	userIDStr := c.Locals("user_id").(string)
-	userID, err := uuid.Parse(userIDStr)
-	if err != nil {
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Invalid user ID"})
+	principal, ok := middleware.GetPrincipal(c)
+	if !ok {
+		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{"error": "Unauthorized"})
 	}
 
-	user, err := h.userUsecase.GetProfile(userID)
+	user, err := h.userUsecase.GetProfile(principal.UserID)
 	if err != nil {
 		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{"error": "User not found"})
 	}
@@ -33,16 +52,194 @@ func (h *UserHandler) GetProfile(c *fiber.Ctx) error {
 	return c.JSON(user)
 }
 
-func (h *UserHandler) BecomeCreator(c *fiber.Ctx) error {
-	userIDStr := c.Locals("user_id").(string)
-	userID, err := uuid.Parse(userIDStr)
+func (h *UserHandler) UpdateProfile(c *fiber.Ctx) error {
+	principal, ok := middleware.GetPrincipal(c)
+	if !ok {
+		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{"error": "Unauthorized"})
+	}
+
+	var req usecase.UpdateProfileInput
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Invalid request"})
+	}
+
+	user, err := h.userUsecase.UpdateProfile(principal.UserID, req)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": err.Error()})
+	}
+
+	return c.JSON(user)
+}
+
+func (h *UserHandler) GetPreferences(c *fiber.Ctx) error {
+	principal, ok := middleware.GetPrincipal(c)
+	if !ok {
+		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{"error": "Unauthorized"})
+	}
+
+	preferences, err := h.userUsecase.GetPreferences(principal.UserID)
 	if err != nil {
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Invalid user ID"})
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "Failed to fetch preferences"})
+	}
+
+	return c.JSON(preferences)
+}
+
+func (h *UserHandler) UpdatePreferences(c *fiber.Ctx) error {
+	principal, ok := middleware.GetPrincipal(c)
+	if !ok {
+		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{"error": "Unauthorized"})
+	}
+
+	var req usecase.UpdatePreferencesInput
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Invalid request"})
 	}
 
-	if err := h.userUsecase.BecomeCreator(userID); err != nil {
+	preferences, err := h.userUsecase.UpdatePreferences(principal.UserID, req)
+	if err != nil {
+		if errors.Is(err, usecase.ErrAgeRequired) {
+			return c.Status(fiber.StatusForbidden).JSON(fiber.Map{"error": err.Error()})
+		}
 		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": err.Error()})
 	}
 
-	return c.JSON(fiber.Map{"message": "You are now a creator!"})
+	return c.JSON(preferences)
+}
+
+// ConfirmAge records the user's birth date, which unlocks NSFW content for
+// adults. It can only be done once.
+func (h *UserHandler) ConfirmAge(c *fiber.Ctx) error {
+	type Request struct {
+		BirthDate string `json:"birth_date"`
+	}
+
+	principal, ok := middleware.GetPrincipal(c)
+	if !ok {
+		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{"error": "Unauthorized"})
+	}
+
+	var req Request
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Invalid request"})
+	}
+
+	birthDate, err := time.Parse(time.DateOnly, req.BirthDate)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "birth_date must be a date in YYYY-MM-DD format"})
+	}
+
+	user, err := h.userUsecase.ConfirmAge(principal.UserID, birthDate)
+	if err != nil {
+		if errors.Is(err, usecase.ErrBirthDateSet) {
+			return c.Status(fiber.StatusConflict).JSON(fiber.Map{"error": err.Error()})
+		}
+		if errors.Is(err, usecase.ErrInvalidBirthDate) {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": err.Error()})
+		}
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "Failed to confirm age"})
+	}
+
+	return c.JSON(fiber.Map{"birth_date": req.BirthDate, "age_confirmed_at": user.AgeConfirmedAt, "adult": user.IsAdult(time.Now())})
+}
+
+// GetPublicProfile returns the public view of any user by username.
+func (h *UserHandler) GetPublicProfile(c *fiber.Ctx) error {
+	profile, err := h.userUsecase.GetPublicProfile(c.Params("username"))
+	if err != nil {
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{"error": "User not found"})
+	}
+
+	return c.JSON(profile)
+}
+
+// BecomeCreator submits a creator role request for admin review.
+func (h *UserHandler) BecomeCreator(c *fiber.Ctx) error {
+	type Request struct {
+		Message string `json:"message"`
+	}
+
+	principal, ok := middleware.GetPrincipal(c)
+	if !ok {
+		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{"error": "Unauthorized"})
+	}
+
+	var req Request
+	if len(c.Body()) > 0 {
+		if err := c.BodyParser(&req); err != nil {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Invalid request"})
+		}
+	}
+
+	request, err := h.roleUsecase.RequestCreator(principal.UserID, req.Message)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": err.Error()})
+	}
+
+	return c.Status(fiber.StatusAccepted).JSON(request)
+}
+
+func (h *UserHandler) ListMyRoleRequests(c *fiber.Ctx) error {
+	principal, ok := middleware.GetPrincipal(c)
+	if !ok {
+		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{"error": "Unauthorized"})
+	}
+
+	requests, err := h.roleUsecase.ListMyRequests(principal.UserID)
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "Failed to fetch role requests"})
+	}
+
+	return c.JSON(requests)
+}
+
+func (h *UserHandler) ListSessions(c *fiber.Ctx) error {
+	principal, ok := middleware.GetPrincipal(c)
+	if !ok {
+		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{"error": "Unauthorized"})
+	}
+
+	sessions, err := h.sessionUsecase.ListSessions(principal.UserID, principal.SessionID)
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "Failed to fetch sessions"})
+	}
+
+	return c.JSON(sessions)
+}
+
+func (h *UserHandler) RevokeSession(c *fiber.Ctx) error {
+	id, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Invalid session ID"})
+	}
+
+	principal, ok := middleware.GetPrincipal(c)
+	if !ok {
+		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{"error": "Unauthorized"})
+	}
+
+	if err := h.sessionUsecase.RevokeSession(principal.UserID, id); err != nil {
+		if errors.Is(err, domain.ErrNotFound) {
+			return c.Status(fiber.StatusNotFound).JSON(fiber.Map{"error": "Session not found"})
+		}
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "Failed to revoke session"})
+	}
+
+	return c.SendStatus(fiber.StatusNoContent)
+}
+
+// RevokeOtherSessions signs out every session except the one making the
+// request.
+func (h *UserHandler) RevokeOtherSessions(c *fiber.Ctx) error {
+	principal, ok := middleware.GetPrincipal(c)
+	if !ok {
+		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{"error": "Unauthorized"})
+	}
+
+	revoked, err := h.sessionUsecase.RevokeOtherSessions(principal.UserID, principal.SessionID)
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "Failed to revoke sessions"})
+	}
+
+	return c.JSON(fiber.Map{"revoked": revoked})
 }
@@ -0,0 +1,45 @@
+package domain
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/lib/pq"
+)
+
+const (
+	ScopeComicsRead    = "comics:read"
+	ScopeComicsWrite   = "comics:write"
+	ScopeChaptersWrite = "chapters:write"
+	ScopeUploadsWrite  = "uploads:write"
+
+	// APIKeyPrefix marks a bearer credential as an API key rather than a JWT.
+	APIKeyPrefix = "tsk_"
+)
+
+// APIKeyScopes lists the scopes that may be granted to an API key.
+var APIKeyScopes = []string{ScopeComicsRead, ScopeComicsWrite, ScopeChaptersWrite, ScopeUploadsWrite}
+
+// APIKey is a personal credential creators use for automation. Only the
+// SHA-256 hash of the key is stored; Prefix is kept so users can tell keys
+// apart.
+type APIKey struct {
+	ID         uuid.UUID      `gorm:"type:uuid;primary_key;" json:"id"`
+	UserID     uuid.UUID      `gorm:"type:uuid;not null;index" json:"user_id"`
+	Name       string         `gorm:"not null" json:"name"`
+	Prefix     string         `gorm:"not null" json:"prefix"`
+	KeyHash    string         `gorm:"uniqueIndex;not null" json:"-"`
+	Scopes     pq.StringArray `gorm:"type:text[]" json:"scopes"`
+	LastUsedAt *time.Time     `json:"last_used_at"`
+	ExpiresAt  *time.Time     `json:"expires_at"`
+	RevokedAt  *time.Time     `json:"revoked_at"`
+	CreatedAt  time.Time      `json:"created_at"`
+}
+
+type APIKeyRepository interface {
+	Create(key *APIKey) error
+	FindByHash(hash string) (*APIKey, error)
+	ListByUserID(userID uuid.UUID) ([]APIKey, error)
+	Revoke(id uuid.UUID, userID uuid.UUID) error
+	TouchLastUsed(id uuid.UUID, at time.Time) error
+}
@@ -0,0 +1,41 @@
+package domain
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+const (
+	AuditActionChangeRole         = "change_role"
+	AuditActionSuspend            = "suspend"
+	AuditActionBan                = "ban"
+	AuditActionReinstate          = "reinstate"
+	AuditActionForcePasswordReset = "force_password_reset"
+	AuditActionRevokeSessions     = "revoke_sessions"
+	AuditActionApproveRoleRequest = "approve_role_request"
+	AuditActionDenyRoleRequest    = "deny_role_request"
+)
+
+// AdminAuditLog records an action an admin took against a user account.
+type AdminAuditLog struct {
+	ID           uuid.UUID              `gorm:"type:uuid;primary_key;" json:"id"`
+	AdminID      uuid.UUID              `gorm:"type:uuid;not null;index" json:"admin_id"`
+	Action       string                 `gorm:"not null;index" json:"action"`
+	TargetUserID uuid.UUID              `gorm:"type:uuid;not null;index" json:"target_user_id"`
+	Reason       string                 `json:"reason"`
+	Details      map[string]interface{} `gorm:"type:jsonb;serializer:json" json:"details,omitempty"`
+	CreatedAt    time.Time              `gorm:"index" json:"created_at"`
+}
+
+type AuditLogFilter struct {
+	AdminID      *uuid.UUID
+	TargetUserID *uuid.UUID
+	Action       string
+	Limit        int
+}
+
+type AuditLogRepository interface {
+	Create(entry *AdminAuditLog) error
+	List(filter AuditLogFilter) ([]AdminAuditLog, error)
+}
@@ -46,44 +46,168 @@ func (m *MultilingualText) Scan(value interface{}) error {
 	return json.Unmarshal(bytes, m)
 }
 
+// In returns the text in locale, falling back to the other language when
+// that translation is empty.
+func (m MultilingualText) In(locale string) string {
+	if locale == "th" && m.Th != "" {
+		return m.Th
+	}
+	if m.En != "" {
+		return m.En
+	}
+	return m.Th
+}
+
 type Comic struct {
-	ID                uuid.UUID        `gorm:"type:uuid;primary_key;" json:"id"`
-	CreatorID         uuid.UUID        `gorm:"type:uuid;not null" json:"creator_id"`
-	Title             MultilingualText `gorm:"type:jsonb;serializer:json" json:"title"`
-	Subtitle          MultilingualText `gorm:"type:jsonb;serializer:json" json:"subtitle"`
-	Description       MultilingualText `gorm:"type:jsonb;serializer:json" json:"description"`
-	Author            string           `json:"author"`
-	Genres            pq.StringArray   `gorm:"type:text[]" json:"genres"`
-	Tags              []Tag            `gorm:"many2many:comic_tags;" json:"tags"`
-	CoverImageURL     string           `json:"cover_image_url"`
-	BannerImageURL    string           `json:"banner_image_url"`
-	Status            ComicStatus     `gorm:"default:'draft'" json:"status"`
-	Visibility        string           `gorm:"default:'public'" json:"visibility"`
-	NSFW              bool             `gorm:"default:false" json:"nsfw"`
-	SchedulePublishAt *time.Time       `json:"schedule_publish_at"`
+	ID             uuid.UUID        `gorm:"type:uuid;primary_key;" json:"id"`
+	CreatorID      uuid.UUID        `gorm:"type:uuid;not null" json:"creator_id"`
+	Title          MultilingualText `gorm:"type:jsonb;serializer:json" json:"title"`
+	Subtitle       MultilingualText `gorm:"type:jsonb;serializer:json" json:"subtitle"`
+	Description    MultilingualText `gorm:"type:jsonb;serializer:json" json:"description"`
+	Author         string           `json:"author"`
+	Genres         pq.StringArray   `gorm:"type:text[]" json:"genres"`
+	Tags           []Tag            `gorm:"many2many:comic_tags;" json:"tags"`
+	CoverImageURL  string           `json:"cover_image_url"`
+	BannerImageURL string           `json:"banner_image_url"`
+	Status         ComicStatus      `gorm:"default:'draft'" json:"status"`
+	Visibility     string           `gorm:"default:'public'" json:"visibility"`
+	NSFW           bool             `gorm:"default:false" json:"nsfw"`
+	// FollowerCount is maintained by FollowRepository and never written
+	// through the comic itself.
+	FollowerCount     int64      `gorm:"->;not null;default:0" json:"follower_count"`
+	SchedulePublishAt *time.Time `json:"schedule_publish_at"`
 	//MonetizationEnabled bool             `gorm:"default:false" json:"monetization_enabled"`
 	//MonetizationType    string           `json:"monetization_type"`
 	//DefaultUnlockType   string           `json:"default_unlock_type"`
 	CreatedAt time.Time `json:"created_at"`
 	UpdatedAt time.Time `json:"updated_at"`
 	Seasons   []Season  `json:"seasons,omitempty"`
+	// Localized holds the text in the reader's preferred language.
+	Localized *LocalizedComic `gorm:"-" json:"localized,omitempty"`
+	// AgeRestricted is set when an NSFW comic was redacted for a reader who
+	// has not confirmed they are an adult.
+	AgeRestricted bool `gorm:"-" json:"age_restricted,omitempty"`
+}
+
+// LocalizedComic is a comic's text resolved to a single language.
+type LocalizedComic struct {
+	Locale      string `json:"locale"`
+	Title       string `json:"title"`
+	Subtitle    string `json:"subtitle"`
+	Description string `json:"description"`
+}
+
+func (c *Comic) Localize(locale string) {
+	c.Localized = &LocalizedComic{
+		Locale:      locale,
+		Title:       c.Title.In(locale),
+		Subtitle:    c.Subtitle.In(locale),
+		Description: c.Description.In(locale),
+	}
+}
+
+// Redact strips an NSFW comic down to what may be shown to readers who are
+// not confirmed adults: no images, description or chapter list.
+func (c *Comic) Redact() {
+	c.Description = MultilingualText{}
+	c.CoverImageURL = ""
+	c.BannerImageURL = ""
+	c.Seasons = nil
+	c.AgeRestricted = true
+}
+
+type ComicSort string
+
+const (
+	SortUpdated    ComicSort = "updated"
+	SortCreated    ComicSort = "created"
+	SortTitle      ComicSort = "title"
+	SortPopularity ComicSort = "popularity"
+)
+
+// ComicFilter narrows public comic listings. The Hidden and ExcludeNSFW
+// fields come from the reader's preferences, the rest from the request.
+// Cursor is the NextCursor of the previous page.
+type ComicFilter struct {
+	ExcludeNSFW  bool
+	HiddenGenres []string
+	HiddenTags   []string
+	Genre        string
+	TagSlug      string
+	Status       ComicStatus
+	NSFW         *bool
+	Author       string
+	CreatorID    *uuid.UUID
+	Sort         ComicSort
+	Cursor       string
+	Limit        int
+}
+
+// ComicPage is one page of a comic listing. NextCursor is empty on the last
+// page; Total counts every comic matching the filter.
+type ComicPage struct {
+	Comics     []Comic `json:"data"`
+	NextCursor string  `json:"next_cursor"`
+	Total      int64   `json:"total"`
 }
 
 type Tag struct {
 	ID           uuid.UUID        `gorm:"type:uuid;primary_key;" json:"id"`
 	Slug         string           `gorm:"uniqueIndex;not null" json:"slug"`
 	Translations []TagTranslation `json:"translations"`
-	CreatedAt    time.Time        `json:"created_at"`
-	UpdatedAt    time.Time        `json:"updated_at"`
+	// ComicCount is the number of public comics using the tag. It is only
+	// filled in by TagRepository listings and lookups.
+	ComicCount *int64    `gorm:"->;-:migration" json:"comic_count,omitempty"`
+	CreatedAt  time.Time `json:"created_at"`
+	UpdatedAt  time.Time `json:"updated_at"`
 }
 
+// TagTranslation is a tag's name in one language. A tag has at most one
+// translation per language.
 type TagTranslation struct {
 	ID       uuid.UUID `gorm:"type:uuid;primary_key;" json:"id"`
-	TagID    uuid.UUID `gorm:"type:uuid;not null;index" json:"tag_id"`
-	Language string    `gorm:"not null;index" json:"language"`
+	TagID    uuid.UUID `gorm:"type:uuid;not null;index;uniqueIndex:idx_tag_translations_tag_language,priority:1" json:"tag_id"`
+	Language string    `gorm:"not null;index;uniqueIndex:idx_tag_translations_tag_language,priority:2" json:"language"`
 	Name     string    `gorm:"not null" json:"name"`
 }
 
+type TagSort string
+
+const (
+	TagSortPopular TagSort = "popular"
+	TagSortName    TagSort = "name"
+)
+
+// TagFilter narrows a tag listing. Query matches the slug and translated
+// names; InUse leaves out tags no public comic uses.
+type TagFilter struct {
+	Query  string
+	InUse  bool
+	Sort   TagSort
+	Limit  int
+	Offset int
+}
+
+// TagRepository stores the shared tag vocabulary.
+type TagRepository interface {
+	// FindOrCreate resolves each tag by slug, creating the ones that do not
+	// exist yet. Translations in languages a tag lacks are added, and
+	// existing ones are only filled in when empty. The returned tags carry
+	// their stored IDs and all translations.
+	FindOrCreate(tags []Tag) ([]Tag, error)
+	List(filter TagFilter) ([]Tag, int64, error)
+	// FindByID and FindBySlug return ErrNotFound when no tag matches.
+	FindByID(id uuid.UUID) (*Tag, error)
+	FindBySlug(slug string) (*Tag, error)
+	// UpdateSlug fails with ErrAlreadyExists if another tag has the slug.
+	UpdateSlug(id uuid.UUID, slug string) error
+	SaveTranslation(tagID uuid.UUID, language, name string) error
+	// Merge moves every comic and missing translation of source onto target
+	// and deletes source.
+	Merge(sourceID, targetID uuid.UUID) error
+	Delete(id uuid.UUID) error
+}
+
 type Season struct {
 	ID           uuid.UUID `gorm:"type:uuid;primary_key;" json:"id"`
 	ComicID      uuid.UUID `gorm:"type:uuid;not null" json:"comic_id"`
@@ -116,9 +240,14 @@ type ComicRepository interface {
 	GetComicByID(id uuid.UUID) (*Comic, error)
 	GetChapterByID(id uuid.UUID) (*Chapter, error)
 	GetSeasonByComicID(comicID uuid.UUID, seasonNumber int) (*Season, error)
-	ListComics() ([]Comic, error)
+	GetSeasonByID(id uuid.UUID) (*Season, error)
+	ListComics(filter ComicFilter) (*ComicPage, error)
 	ListComicsByCreatorID(creatorID uuid.UUID) ([]Comic, error)
 	ListComicsByAuthor(author string) ([]Comic, error)
-	UpdateComic(comic *Comic) error
+	ListPublicComicsByCreatorID(creatorID uuid.UUID) ([]Comic, error)
+	// UpdateComic saves the comic's own fields and, when tags is not nil,
+	// replaces its tags in the same transaction.
+	UpdateComic(comic *Comic, tags []Tag) error
 	DeleteComic(id uuid.UUID) error
+	ListComicsWithChaptersByCreatorID(creatorID uuid.UUID) ([]Comic, error)
 }
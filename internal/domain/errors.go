@@ -1,8 +1,50 @@
 package domain
 
-import "errors"
+import (
+	"errors"
+	"fmt"
+	"time"
+)
 
 var (
-	ErrUnauthorized = errors.New("unauthorized action")
-	ErrNotFound     = errors.New("resource not found")
+	ErrUnauthorized       = errors.New("unauthorized action")
+	ErrNotFound           = errors.New("resource not found")
+	ErrInvalidCredentials = errors.New("invalid credentials")
+	ErrInvalidToken       = errors.New("invalid or expired token")
+	// ErrPasswordResetRequired is returned by a password login after an admin
+	// forced a reset; the user has to choose a new password first.
+	ErrPasswordResetRequired = errors.New("password reset required")
+	// ErrInvalidCursor is returned for a pagination cursor that is malformed
+	// or was issued for a different sort order.
+	ErrInvalidCursor = errors.New("invalid cursor")
+	// ErrRequestReviewed is returned when reviewing a role request that is
+	// no longer pending.
+	ErrRequestReviewed = errors.New("request has already been reviewed")
+	// ErrAlreadyExists is returned by repositories when a write would break a
+	// unique constraint.
+	ErrAlreadyExists = errors.New("resource already exists")
 )
+
+// ThrottledError is returned when a caller must wait before trying again.
+type ThrottledError struct {
+	RetryAfter time.Duration
+}
+
+func (e *ThrottledError) Error() string {
+	return fmt.Sprintf("too many attempts, retry after %s", e.RetryAfter.Round(time.Second))
+}
+
+// SuspendedError is returned when a suspended or banned account tries to sign
+// in or use the API.
+type SuspendedError struct {
+	Status UserStatus
+	Reason string
+	Until  *time.Time
+}
+
+func (e *SuspendedError) Error() string {
+	if e.Until != nil {
+		return fmt.Sprintf("account %s until %s", e.Status, e.Until.Format(time.RFC3339))
+	}
+	return fmt.Sprintf("account %s", e.Status)
+}
@@ -0,0 +1,37 @@
+package domain
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// CreatorFollow records a reader following a creator.
+type CreatorFollow struct {
+	FollowerID uuid.UUID `gorm:"type:uuid;primary_key;" json:"follower_id"`
+	CreatorID  uuid.UUID `gorm:"type:uuid;primary_key;index" json:"creator_id"`
+	CreatedAt  time.Time `json:"created_at"`
+}
+
+// ComicSubscription records a reader subscribing to a comic's new chapters.
+type ComicSubscription struct {
+	UserID    uuid.UUID `gorm:"type:uuid;primary_key;" json:"user_id"`
+	ComicID   uuid.UUID `gorm:"type:uuid;primary_key;index" json:"comic_id"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// FollowRepository keeps the follow tables and the denormalized follower
+// counts on users and comics in step. Follow and Subscribe report whether a
+// new row was created, Unfollow and Unsubscribe whether one was removed.
+type FollowRepository interface {
+	FollowCreator(followerID, creatorID uuid.UUID) (bool, error)
+	UnfollowCreator(followerID, creatorID uuid.UUID) (bool, error)
+	Subscribe(userID, comicID uuid.UUID) (bool, error)
+	Unsubscribe(userID, comicID uuid.UUID) (bool, error)
+	IsFollowing(followerID, creatorID uuid.UUID) (bool, error)
+	IsSubscribed(userID, comicID uuid.UUID) (bool, error)
+	ListFollowedCreators(followerID uuid.UUID) ([]User, error)
+	ListSubscribedComics(userID uuid.UUID) ([]Comic, error)
+	ListFollowerIDs(creatorID uuid.UUID) ([]uuid.UUID, error)
+	ListSubscriberIDs(comicID uuid.UUID) ([]uuid.UUID, error)
+}
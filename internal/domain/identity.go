@@ -0,0 +1,42 @@
+package domain
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// ExternalIdentity links an account at an OpenID Connect provider to a user.
+type ExternalIdentity struct {
+	ID          uuid.UUID  `gorm:"type:uuid;primary_key;" json:"id"`
+	UserID      uuid.UUID  `gorm:"type:uuid;not null;index" json:"user_id"`
+	Provider    string     `gorm:"not null;uniqueIndex:idx_external_identity_subject" json:"provider"`
+	Subject     string     `gorm:"not null;uniqueIndex:idx_external_identity_subject" json:"-"`
+	Email       string     `json:"email"`
+	LastLoginAt *time.Time `json:"last_login_at"`
+	CreatedAt   time.Time  `json:"created_at"`
+}
+
+// OIDCLoginState remembers an authorization request between the redirect to
+// the provider and its callback. LinkUserID is set when a signed-in user is
+// linking a new identity rather than logging in.
+type OIDCLoginState struct {
+	ID           uuid.UUID  `gorm:"type:uuid;primary_key;"`
+	StateHash    string     `gorm:"uniqueIndex;not null"`
+	Provider     string     `gorm:"not null"`
+	CodeVerifier string     `gorm:"not null"`
+	Nonce        string     `gorm:"not null"`
+	LinkUserID   *uuid.UUID `gorm:"type:uuid"`
+	ExpiresAt    time.Time  `gorm:"not null;index"`
+	CreatedAt    time.Time
+}
+
+type IdentityRepository interface {
+	Create(identity *ExternalIdentity) error
+	FindByProviderSubject(provider, subject string) (*ExternalIdentity, error)
+	ListByUserID(userID uuid.UUID) ([]ExternalIdentity, error)
+	Delete(id uuid.UUID, userID uuid.UUID) error
+	TouchLastLogin(id uuid.UUID, at time.Time) error
+	CreateLoginState(state *OIDCLoginState) error
+	ConsumeLoginState(stateHash string) (*OIDCLoginState, error)
+}
@@ -0,0 +1,36 @@
+package domain
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+const (
+	LoginReasonSuccess            = "success"
+	LoginReasonInvalidCredentials = "invalid_credentials"
+	LoginReasonInvalidTwoFactor   = "invalid_two_factor"
+	LoginReasonThrottled          = "throttled"
+)
+
+// LoginAttempt is the audit record of a single sign in attempt. Failed
+// attempts also drive login throttling.
+type LoginAttempt struct {
+	ID         uuid.UUID  `gorm:"type:uuid;primary_key;" json:"id"`
+	Identifier string     `gorm:"not null;index:idx_login_attempts_identifier_created" json:"identifier"`
+	IP         string     `gorm:"index:idx_login_attempts_ip_created" json:"ip"`
+	UserAgent  string     `json:"user_agent"`
+	UserID     *uuid.UUID `gorm:"type:uuid;index" json:"user_id"`
+	Success    bool       `gorm:"not null" json:"success"`
+	Reason     string     `gorm:"not null" json:"reason"`
+	CreatedAt  time.Time  `gorm:"index:idx_login_attempts_identifier_created;index:idx_login_attempts_ip_created" json:"created_at"`
+}
+
+type LoginAttemptRepository interface {
+	Create(attempt *LoginAttempt) error
+	LastSuccessAt(identifier string) (*time.Time, error)
+	CountIdentifierFailures(identifier string, since time.Time) (int64, *time.Time, error)
+	CountIPFailures(ip string, since time.Time) (int64, *time.Time, error)
+	List(identifier, ip string, failedOnly bool, limit int) ([]LoginAttempt, error)
+	ListByUserID(userID uuid.UUID, limit int) ([]LoginAttempt, error)
+}
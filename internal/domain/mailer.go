@@ -0,0 +1,6 @@
+package domain
+
+// Mailer delivers transactional email such as password reset links.
+type Mailer interface {
+	Send(to, subject, body string) error
+}
@@ -0,0 +1,74 @@
+package domain
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type NotificationType string
+
+const (
+	NotificationNewChapter  NotificationType = "new_chapter"
+	NotificationNewFollower NotificationType = "new_follower"
+	// NotificationCommentReply is reserved for replies once comments exist.
+	NotificationCommentReply     NotificationType = "comment_reply"
+	NotificationModerationResult NotificationType = "moderation_result"
+)
+
+// NotificationTypes lists every type a user can switch on or off.
+var NotificationTypes = []NotificationType{
+	NotificationNewChapter,
+	NotificationNewFollower,
+	NotificationCommentReply,
+	NotificationModerationResult,
+}
+
+// Notification is an in-app message for one user. Data carries the IDs a
+// client needs to link to the subject, such as comic_id and chapter_id.
+type Notification struct {
+	ID        uuid.UUID              `gorm:"type:uuid;primary_key;" json:"id"`
+	UserID    uuid.UUID              `gorm:"type:uuid;not null;index:idx_notifications_user_created,priority:1" json:"-"`
+	Type      NotificationType       `gorm:"not null" json:"type"`
+	Title     string                 `gorm:"not null" json:"title"`
+	Body      string                 `json:"body"`
+	Data      map[string]interface{} `gorm:"type:jsonb;serializer:json" json:"data,omitempty"`
+	ReadAt    *time.Time             `json:"read_at"`
+	CreatedAt time.Time              `gorm:"index:idx_notifications_user_created,priority:2" json:"created_at"`
+}
+
+// NotificationPreference switches one notification type off or back on for
+// a user. Types without a row are enabled.
+type NotificationPreference struct {
+	UserID  uuid.UUID        `gorm:"type:uuid;primary_key;" json:"-"`
+	Type    NotificationType `gorm:"primary_key" json:"type"`
+	Enabled bool             `gorm:"not null" json:"enabled"`
+}
+
+// NotificationFilter narrows a notification listing. Cursor is the next
+// cursor returned with the previous page.
+type NotificationFilter struct {
+	UnreadOnly bool
+	Cursor     string
+	Limit      int
+}
+
+type NotificationRepository interface {
+	CreateMany(notifications []Notification) error
+	// List returns one page of notifications, newest first, and the cursor
+	// of the next page, which is empty on the last page.
+	List(userID uuid.UUID, filter NotificationFilter) ([]Notification, string, error)
+	// ListByUserID returns all of the user's notifications, newest first.
+	ListByUserID(userID uuid.UUID) ([]Notification, error)
+	CountUnread(userID uuid.UUID) (int64, error)
+	MarkRead(userID uuid.UUID, ids []uuid.UUID, at time.Time) (int64, error)
+	MarkAllRead(userID uuid.UUID, at time.Time) (int64, error)
+	ListPreferences(userID uuid.UUID) ([]NotificationPreference, error)
+	SavePreferences(preferences []NotificationPreference) error
+	// FilterRecipients returns the users in userIDs that have not switched
+	// notificationType off.
+	FilterRecipients(userIDs []uuid.UUID, notificationType NotificationType) ([]uuid.UUID, error)
+	// HasFollowerNotification reports whether the creator has already been
+	// notified that followerID follows them.
+	HasFollowerNotification(creatorID, followerID uuid.UUID) (bool, error)
+}
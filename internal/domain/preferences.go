@@ -0,0 +1,44 @@
+package domain
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/lib/pq"
+)
+
+const (
+	LocaleEn = "en"
+	LocaleTh = "th"
+
+	ReadingVertical = "vertical"
+	ReadingLTR      = "ltr"
+	ReadingRTL      = "rtl"
+)
+
+// UserPreferences are a reader's content and display settings. Users without
+// a stored row get DefaultPreferences.
+type UserPreferences struct {
+	UserID           uuid.UUID      `gorm:"type:uuid;primary_key;" json:"-"`
+	Locale           string         `gorm:"not null;default:'en'" json:"locale"`
+	ShowNSFW         bool           `gorm:"not null;default:false" json:"show_nsfw"`
+	HiddenGenres     pq.StringArray `gorm:"type:text[]" json:"hidden_genres"`
+	HiddenTags       pq.StringArray `gorm:"type:text[]" json:"hidden_tags"`
+	ReadingDirection string         `gorm:"not null;default:'vertical'" json:"reading_direction"`
+	UpdatedAt        time.Time      `json:"updated_at"`
+}
+
+func DefaultPreferences(userID uuid.UUID) *UserPreferences {
+	return &UserPreferences{
+		UserID:           userID,
+		Locale:           LocaleEn,
+		HiddenGenres:     pq.StringArray{},
+		HiddenTags:       pq.StringArray{},
+		ReadingDirection: ReadingVertical,
+	}
+}
+
+type PreferencesRepository interface {
+	FindByUserID(userID uuid.UUID) (*UserPreferences, error)
+	Save(preferences *UserPreferences) error
+}
@@ -0,0 +1,41 @@
+package domain
+
+import "github.com/google/uuid"
+
+// ScopeAll grants every scope. Interactive sessions carry it.
+const ScopeAll = "*"
+
+// Principal is the authenticated caller of a request.
+type Principal struct {
+	UserID        uuid.UUID
+	Role          UserRole
+	SessionID     uuid.UUID
+	TokenID       string
+	Scopes        []string
+	EmailVerified bool
+	// APIKeyID is set when the request was authenticated with an API key
+	// instead of an interactive session.
+	APIKeyID *uuid.UUID
+}
+
+func (p *Principal) HasScope(scope string) bool {
+	for _, s := range p.Scopes {
+		if s == ScopeAll || s == scope {
+			return true
+		}
+	}
+	return false
+}
+
+func (p *Principal) IsAPIKey() bool {
+	return p.APIKeyID != nil
+}
+
+func (p *Principal) HasRole(roles ...UserRole) bool {
+	for _, role := range roles {
+		if p.Role == role {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,55 @@
+package domain
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type RoleRequestStatus string
+
+const (
+	RoleRequestPending  RoleRequestStatus = "pending"
+	RoleRequestApproved RoleRequestStatus = "approved"
+	RoleRequestDenied   RoleRequestStatus = "denied"
+)
+
+// RoleRequest is a user's application for a new role, reviewed by an admin.
+type RoleRequest struct {
+	ID            uuid.UUID         `gorm:"type:uuid;primary_key;" json:"id"`
+	UserID        uuid.UUID         `gorm:"type:uuid;not null;index" json:"user_id"`
+	RequestedRole UserRole          `gorm:"not null" json:"requested_role"`
+	Status        RoleRequestStatus `gorm:"default:'pending';index" json:"status"`
+	Message       string            `json:"message"`
+	ReviewNote    string            `json:"review_note"`
+	ReviewedBy    *uuid.UUID        `gorm:"type:uuid" json:"reviewed_by"`
+	ReviewedAt    *time.Time        `json:"reviewed_at"`
+	CreatedAt     time.Time         `json:"created_at"`
+	UpdatedAt     time.Time         `json:"updated_at"`
+}
+
+// RoleChange is the audit record written every time a user's role changes.
+type RoleChange struct {
+	ID        uuid.UUID  `gorm:"type:uuid;primary_key;" json:"id"`
+	UserID    uuid.UUID  `gorm:"type:uuid;not null;index" json:"user_id"`
+	OldRole   UserRole   `gorm:"not null" json:"old_role"`
+	NewRole   UserRole   `gorm:"not null" json:"new_role"`
+	ChangedBy uuid.UUID  `gorm:"type:uuid;not null" json:"changed_by"`
+	RequestID *uuid.UUID `gorm:"type:uuid" json:"request_id"`
+	Reason    string     `json:"reason"`
+	CreatedAt time.Time  `json:"created_at"`
+}
+
+type RoleRepository interface {
+	CreateRequest(request *RoleRequest) error
+	FindRequestByID(id uuid.UUID) (*RoleRequest, error)
+	FindPendingRequestByUserID(userID uuid.UUID) (*RoleRequest, error)
+	ListRequests(status RoleRequestStatus) ([]RoleRequest, error)
+	ListRequestsByUserID(userID uuid.UUID) ([]RoleRequest, error)
+	// ReviewRequest saves the review of a pending request and, when change is
+	// not nil, applies the role change in the same transaction. It fails with
+	// ErrRequestReviewed if the request is no longer pending.
+	ReviewRequest(request *RoleRequest, change *RoleChange) error
+	ApplyRoleChange(change *RoleChange) error
+	ListRoleChanges(userID uuid.UUID) ([]RoleChange, error)
+}
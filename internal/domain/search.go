@@ -0,0 +1,15 @@
+package domain
+
+// SearchRepository searches the public catalog. Filter narrows the results
+// the same way it narrows listings; its Sort and Cursor are ignored because
+// results are ordered by relevance.
+//
+// The Suggest methods back typeahead: they only prefix-match a lowercased
+// prefix and return at most Limit (or limit) rows, so they stay cheap enough
+// to run on every keystroke.
+type SearchRepository interface {
+	SearchComics(query string, filter ComicFilter, offset int) ([]Comic, int64, error)
+	SuggestComics(prefix string, filter ComicFilter) ([]Comic, error)
+	SuggestAuthors(prefix string, filter ComicFilter) ([]string, error)
+	SuggestTags(prefix string, filter ComicFilter) ([]Tag, error)
+}
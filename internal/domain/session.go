@@ -0,0 +1,35 @@
+package domain
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Session is one signed-in device. Its ID is the session ID carried by the
+// access tokens and refresh tokens issued for it.
+type Session struct {
+	ID         uuid.UUID  `gorm:"type:uuid;primary_key;" json:"id"`
+	UserID     uuid.UUID  `gorm:"type:uuid;not null;index" json:"-"`
+	Device     string     `json:"device"`
+	UserAgent  string     `json:"user_agent"`
+	IP         string     `json:"ip"`
+	LastSeenAt time.Time  `json:"last_seen_at"`
+	RevokedAt  *time.Time `json:"-"`
+	CreatedAt  time.Time  `json:"created_at"`
+	// Current marks the session making the request in listings.
+	Current bool `gorm:"-" json:"current"`
+}
+
+type SessionRepository interface {
+	Create(session *Session) error
+	FindByID(id uuid.UUID) (*Session, error)
+	ListActiveByUserID(userID uuid.UUID, since time.Time) ([]Session, error)
+	// ListByUserID returns every session of the user, including revoked and
+	// expired ones, newest first.
+	ListByUserID(userID uuid.UUID) ([]Session, error)
+	Touch(id uuid.UUID, at time.Time) error
+	Revoke(id uuid.UUID, userID uuid.UUID) error
+	RevokeOthers(userID uuid.UUID, keepID uuid.UUID) (int64, error)
+	RevokeAll(userID uuid.UUID) error
+}
@@ -0,0 +1,83 @@
+package domain
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// RefreshToken is a long-lived, single-use credential exchanged for a new
+// access token. Only the SHA-256 hash of the token is stored.
+type RefreshToken struct {
+	ID           uuid.UUID  `gorm:"type:uuid;primary_key;" json:"id"`
+	UserID       uuid.UUID  `gorm:"type:uuid;not null;index" json:"user_id"`
+	SessionID    uuid.UUID  `gorm:"type:uuid;not null;index" json:"session_id"`
+	TokenHash    string     `gorm:"uniqueIndex;not null" json:"-"`
+	ExpiresAt    time.Time  `gorm:"not null" json:"expires_at"`
+	RevokedAt    *time.Time `json:"revoked_at"`
+	ReplacedByID *uuid.UUID `gorm:"type:uuid" json:"replaced_by_id"`
+	CreatedAt    time.Time  `json:"created_at"`
+}
+
+// RevokedToken records an access token that must be rejected before it
+// expires. Rows can be discarded once ExpiresAt has passed.
+type RevokedToken struct {
+	JTI       string    `gorm:"primaryKey" json:"jti"`
+	UserID    uuid.UUID `gorm:"type:uuid;not null;index" json:"user_id"`
+	ExpiresAt time.Time `gorm:"not null;index" json:"expires_at"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// PasswordResetToken is a single-use token mailed to a user so they can set a
+// new password. Only the SHA-256 hash of the token is stored.
+type PasswordResetToken struct {
+	ID        uuid.UUID  `gorm:"type:uuid;primary_key;" json:"id"`
+	UserID    uuid.UUID  `gorm:"type:uuid;not null;index" json:"user_id"`
+	TokenHash string     `gorm:"uniqueIndex;not null" json:"-"`
+	ExpiresAt time.Time  `gorm:"not null" json:"expires_at"`
+	UsedAt    *time.Time `json:"used_at"`
+	CreatedAt time.Time  `json:"created_at"`
+}
+
+// EmailVerificationToken is mailed on sign up to prove ownership of the
+// account's email address. Only the SHA-256 hash of the token is stored.
+type EmailVerificationToken struct {
+	ID        uuid.UUID  `gorm:"type:uuid;primary_key;" json:"id"`
+	UserID    uuid.UUID  `gorm:"type:uuid;not null;index" json:"user_id"`
+	TokenHash string     `gorm:"uniqueIndex;not null" json:"-"`
+	ExpiresAt time.Time  `gorm:"not null" json:"expires_at"`
+	UsedAt    *time.Time `json:"used_at"`
+	CreatedAt time.Time  `json:"created_at"`
+}
+
+// RecoveryCode is a single-use fallback for a lost two-factor device. Only
+// the SHA-256 hash of the code is stored.
+type RecoveryCode struct {
+	ID        uuid.UUID  `gorm:"type:uuid;primary_key;" json:"id"`
+	UserID    uuid.UUID  `gorm:"type:uuid;not null;index" json:"user_id"`
+	CodeHash  string     `gorm:"not null;index" json:"-"`
+	UsedAt    *time.Time `json:"used_at"`
+	CreatedAt time.Time  `json:"created_at"`
+}
+
+type TokenRepository interface {
+	CreateRefreshToken(token *RefreshToken) error
+	FindRefreshTokenByHash(hash string) (*RefreshToken, error)
+	RotateRefreshToken(oldID uuid.UUID, next *RefreshToken) error
+	RevokeSessionRefreshTokens(sessionID uuid.UUID) error
+	RevokeUserRefreshTokens(userID uuid.UUID) error
+	RevokeAccessToken(token *RevokedToken) error
+	IsAccessTokenRevoked(jti string) (bool, error)
+
+	CreatePasswordResetToken(token *PasswordResetToken) error
+	FindPasswordResetTokenByHash(hash string) (*PasswordResetToken, error)
+	ConsumePasswordResetToken(id uuid.UUID) error
+	InvalidateUserPasswordResetTokens(userID uuid.UUID) error
+
+	CreateEmailVerificationToken(token *EmailVerificationToken) error
+	FindEmailVerificationTokenByHash(hash string) (*EmailVerificationToken, error)
+	ConsumeEmailVerificationToken(id uuid.UUID) error
+
+	ReplaceRecoveryCodes(userID uuid.UUID, codes []RecoveryCode) error
+	ConsumeRecoveryCode(userID uuid.UUID, hash string) error
+}
@@ -14,14 +14,131 @@ const (
 	RoleAdmin   UserRole = "admin"
 )
 
+// AdultAge is the minimum age for NSFW content.
+const AdultAge = 18
+
+type UserStatus string
+
+const (
+	UserActive    UserStatus = "active"
+	UserSuspended UserStatus = "suspended"
+	UserBanned    UserStatus = "banned"
+)
+
 type User struct {
-	ID           uuid.UUID `gorm:"type:uuid;primary_key;" json:"id"`
-	Username     string    `gorm:"unique;not null" json:"username"`
-	Email        string    `gorm:"unique;not null" json:"email"`
-	PasswordHash string    `gorm:"not null" json:"-"`
-	Role         UserRole  `gorm:"default:'user'" json:"role"`
-	CreatedAt    time.Time `json:"created_at"`
-	UpdatedAt    time.Time `json:"updated_at"`
+	ID              uuid.UUID  `gorm:"type:uuid;primary_key;" json:"id"`
+	Username        string     `gorm:"unique;not null" json:"username"`
+	Email           string     `gorm:"unique;not null" json:"email"`
+	PasswordHash    string     `gorm:"not null" json:"-"`
+	Role            UserRole   `gorm:"default:'user'" json:"role"`
+	EmailVerifiedAt *time.Time `json:"email_verified_at"`
+	// Profile fields, shown publicly through PublicProfile.
+	DisplayName string           `json:"display_name"`
+	AvatarURL   string           `json:"avatar_url"`
+	Bio         MultilingualText `gorm:"type:jsonb;serializer:json" json:"bio"`
+	Links       []ProfileLink    `gorm:"type:jsonb;serializer:json" json:"links"`
+	// FollowerCount is maintained by FollowRepository and never written
+	// through the user itself.
+	FollowerCount int64 `gorm:"->;not null;default:0" json:"follower_count"`
+	// TwoFactorSecret holds the TOTP secret, set during enrollment and only
+	// enforced once TwoFactorEnabledAt is set.
+	TwoFactorSecret    string     `json:"-"`
+	TwoFactorEnabledAt *time.Time `json:"two_factor_enabled_at"`
+	// TwoFactorLastStep is the TOTP time step of the last accepted code.
+	// Codes from that step or earlier are refused so they cannot be replayed.
+	TwoFactorLastStep int64 `gorm:"not null;default:0" json:"-"`
+	// TokensRevokedAt invalidates every access token issued before it.
+	TokensRevokedAt *time.Time `json:"-"`
+	// Status is set by admins. SuspendedUntil, when set, is when a
+	// suspension or ban ends.
+	Status           UserStatus `gorm:"default:'active';index" json:"status"`
+	SuspensionReason string     `json:"suspension_reason,omitempty"`
+	SuspendedUntil   *time.Time `json:"suspended_until,omitempty"`
+	// PasswordResetRequired blocks password logins until the user sets a new
+	// password through the reset flow.
+	PasswordResetRequired bool `gorm:"default:false" json:"password_reset_required"`
+	// DeletionScheduledAt is when a requested account deletion takes effect.
+	// Until then the owner can cancel it.
+	DeletionScheduledAt *time.Time `json:"deletion_scheduled_at"`
+	// BirthDate is self-declared and gates NSFW content. It can only be set
+	// once; AgeConfirmedAt records when.
+	BirthDate      *time.Time `gorm:"type:date" json:"birth_date,omitempty"`
+	AgeConfirmedAt *time.Time `json:"age_confirmed_at,omitempty"`
+	// AnonymizedAt is set once the account has been deleted and its personal
+	// data scrubbed. The row is kept so references stay valid.
+	AnonymizedAt *time.Time `json:"-"`
+	// Preferences is filled in when the owner fetches their own account.
+	Preferences *UserPreferences `gorm:"-" json:"preferences,omitempty"`
+	CreatedAt   time.Time        `json:"created_at"`
+	UpdatedAt   time.Time        `json:"updated_at"`
+}
+
+// IsRestricted reports whether the account is suspended or banned at now.
+// Restrictions with a SuspendedUntil in the past have lapsed even if the
+// status has not been reset yet.
+func (u *User) IsRestricted(now time.Time) bool {
+	if u.Status == "" || u.Status == UserActive {
+		return false
+	}
+	return u.SuspendedUntil == nil || now.Before(*u.SuspendedUntil)
+}
+
+// IsAdult reports whether the user has confirmed a birth date at least
+// AdultAge years before now.
+func (u *User) IsAdult(now time.Time) bool {
+	if u.BirthDate == nil {
+		return false
+	}
+	return !u.BirthDate.AddDate(AdultAge, 0, 0).After(now)
+}
+
+// ProfileLink is a social or personal link shown on a user's profile.
+type ProfileLink struct {
+	Label string `json:"label"`
+	URL   string `json:"url"`
+}
+
+// PublicProfile is the view of a user that anyone may see. It deliberately
+// has no email or account state.
+type PublicProfile struct {
+	ID          uuid.UUID        `json:"id"`
+	Username    string           `json:"username"`
+	DisplayName string           `json:"display_name"`
+	AvatarURL   string           `json:"avatar_url"`
+	Bio         MultilingualText `json:"bio"`
+	Links       []ProfileLink    `json:"links"`
+	Role        UserRole         `json:"role"`
+	// FollowerCount is only meaningful for creators.
+	FollowerCount int64     `json:"follower_count"`
+	CreatedAt     time.Time `json:"created_at"`
+}
+
+func (u *User) PublicProfile() *PublicProfile {
+	links := u.Links
+	if links == nil {
+		links = []ProfileLink{}
+	}
+	return &PublicProfile{
+		ID:            u.ID,
+		Username:      u.Username,
+		DisplayName:   u.DisplayName,
+		AvatarURL:     u.AvatarURL,
+		Bio:           u.Bio,
+		Links:         links,
+		Role:          u.Role,
+		FollowerCount: u.FollowerCount,
+		CreatedAt:     u.CreatedAt,
+	}
+}
+
+// UserFilter narrows an admin user search. Query matches username, email
+// and display name.
+type UserFilter struct {
+	Query  string
+	Role   UserRole
+	Status UserStatus
+	Limit  int
+	Offset int
 }
 
 type UserRepository interface {
@@ -29,4 +146,29 @@ type UserRepository interface {
 	Update(user *User) error
 	FindByEmailOrUsername(identifier string) (*User, error)
 	FindByID(id uuid.UUID) (*User, error)
+	FindByEmail(email string) (*User, error)
+	FindByUsername(username string) (*User, error)
+	UpdateProfile(user *User) error
+	UpdatePassword(id uuid.UUID, passwordHash string) error
+	SetTokensRevokedAt(id uuid.UUID, at time.Time) error
+	MarkEmailVerified(id uuid.UUID, at time.Time) error
+	SetTwoFactorSecret(id uuid.UUID, secret string) error
+	// EnableTwoFactor turns two-factor on and stores the recovery codes in
+	// one transaction, replacing any earlier codes.
+	EnableTwoFactor(id uuid.UUID, at time.Time, codes []RecoveryCode) error
+	// DisableTwoFactor turns two-factor off and deletes the recovery codes.
+	DisableTwoFactor(id uuid.UUID) error
+	// UseTwoFactorStep records step as the last accepted TOTP step. It fails
+	// with ErrInvalidToken unless step is newer than the one recorded.
+	UseTwoFactorStep(id uuid.UUID, step int64) error
+	SetDeletionScheduledAt(id uuid.UUID, at *time.Time) error
+	// ListDueDeletions returns accounts whose deletion is due, leaving out
+	// the IDs in exclude.
+	ListDueDeletions(before time.Time, exclude []uuid.UUID, limit int) ([]User, error)
+	Anonymize(id uuid.UUID, at time.Time) error
+	Search(filter UserFilter) ([]User, int64, error)
+	SetStatus(id uuid.UUID, status UserStatus, reason string, until *time.Time) error
+	SetPasswordResetRequired(id uuid.UUID, required bool) error
+	SetBirthDate(id uuid.UUID, birthDate time.Time, at time.Time) error
+	LiftExpiredRestrictions(now time.Time) (int64, error)
 }
@@ -0,0 +1,140 @@
+package keyring
+
+import (
+	"crypto"
+	"crypto/ed25519"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// keyConfig is one entry of the JSON file named by JWT_KEYS_FILE, e.g.
+//
+//	[{"kid": "2026-01", "alg": "EdDSA", "private_key_file": "keys/2026-01.pem",
+//	  "active_from": "2026-01-01T00:00:00Z", "retire_at": "2026-04-01T00:00:00Z",
+//	  "expires_at": "2026-05-01T00:00:00Z"}]
+//
+// HS256 keys read their secret from the environment variable in secret_env.
+// Entries with only public_key_file are used for verification.
+type keyConfig struct {
+	ID             string    `json:"kid"`
+	Algorithm      string    `json:"alg"`
+	PrivateKeyFile string    `json:"private_key_file"`
+	PublicKeyFile  string    `json:"public_key_file"`
+	SecretEnv      string    `json:"secret_env"`
+	ActiveFrom     time.Time `json:"active_from"`
+	RetireAt       time.Time `json:"retire_at"`
+	ExpiresAt      time.Time `json:"expires_at"`
+}
+
+// Load builds the key ring from JWT_KEYS_FILE. Without it, the ring holds a
+// single HS256 key read from JWT_SECRET so existing deployments keep working.
+func Load() (*KeyRing, error) {
+	path := os.Getenv("JWT_KEYS_FILE")
+	if path == "" {
+		secret := os.Getenv("JWT_SECRET")
+		if secret == "" {
+			return nil, errors.New("keyring: JWT_KEYS_FILE or JWT_SECRET must be set")
+		}
+		return New(&Key{ID: "default", Algorithm: AlgHS256, PrivateKey: []byte(secret), PublicKey: []byte(secret)})
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var configs []keyConfig
+	if err := json.Unmarshal(data, &configs); err != nil {
+		return nil, fmt.Errorf("keyring: invalid %s: %w", path, err)
+	}
+
+	keys := make([]*Key, 0, len(configs))
+	for _, cfg := range configs {
+		key, err := cfg.load()
+		if err != nil {
+			return nil, err
+		}
+		keys = append(keys, key)
+	}
+
+	return New(keys...)
+}
+
+func (cfg keyConfig) load() (*Key, error) {
+	key := &Key{
+		ID:         cfg.ID,
+		Algorithm:  cfg.Algorithm,
+		ActiveFrom: cfg.ActiveFrom,
+		RetireAt:   cfg.RetireAt,
+		ExpiresAt:  cfg.ExpiresAt,
+	}
+
+	if cfg.Algorithm == AlgHS256 {
+		secret := os.Getenv(cfg.SecretEnv)
+		if secret == "" {
+			return nil, fmt.Errorf("keyring: secret for key %q is empty", cfg.ID)
+		}
+		key.PrivateKey, key.PublicKey = []byte(secret), []byte(secret)
+		return key, nil
+	}
+
+	if cfg.PrivateKeyFile != "" {
+		pem, err := os.ReadFile(cfg.PrivateKeyFile)
+		if err != nil {
+			return nil, err
+		}
+		key.PrivateKey, key.PublicKey, err = parsePrivateKey(cfg.Algorithm, pem)
+		if err != nil {
+			return nil, fmt.Errorf("keyring: key %q: %w", cfg.ID, err)
+		}
+		return key, nil
+	}
+
+	if cfg.PublicKeyFile != "" {
+		pem, err := os.ReadFile(cfg.PublicKeyFile)
+		if err != nil {
+			return nil, err
+		}
+		key.PublicKey, err = parsePublicKey(cfg.Algorithm, pem)
+		if err != nil {
+			return nil, fmt.Errorf("keyring: key %q: %w", cfg.ID, err)
+		}
+		return key, nil
+	}
+
+	return nil, fmt.Errorf("keyring: key %q has no key material", cfg.ID)
+}
+
+func parsePrivateKey(alg string, pem []byte) (crypto.PrivateKey, crypto.PublicKey, error) {
+	switch alg {
+	case AlgRS256:
+		priv, err := jwt.ParseRSAPrivateKeyFromPEM(pem)
+		if err != nil {
+			return nil, nil, err
+		}
+		return priv, &priv.PublicKey, nil
+	case AlgEdDSA:
+		priv, err := jwt.ParseEdPrivateKeyFromPEM(pem)
+		if err != nil {
+			return nil, nil, err
+		}
+		edPriv := priv.(ed25519.PrivateKey)
+		return edPriv, edPriv.Public(), nil
+	}
+	return nil, nil, fmt.Errorf("unsupported algorithm %q", alg)
+}
+
+func parsePublicKey(alg string, pem []byte) (crypto.PublicKey, error) {
+	switch alg {
+	case AlgRS256:
+		return jwt.ParseRSAPublicKeyFromPEM(pem)
+	case AlgEdDSA:
+		return jwt.ParseEdPublicKeyFromPEM(pem)
+	}
+	return nil, fmt.Errorf("unsupported algorithm %q", alg)
+}
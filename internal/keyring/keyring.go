@@ -0,0 +1,195 @@
+// Package keyring manages the keys used to sign and verify JWTs. It supports
+// HS256, RS256 and EdDSA keys identified by the "kid" header, several
+// verification keys at once, and rotation scheduled through activation and
+// retirement times.
+package keyring
+
+import (
+	"crypto"
+	"crypto/ed25519"
+	"crypto/rsa"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"math/big"
+	"sort"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+const (
+	AlgHS256 = "HS256"
+	AlgRS256 = "RS256"
+	AlgEdDSA = "EdDSA"
+)
+
+var ErrNoSigningKey = errors.New("keyring: no active signing key")
+
+// Key is a single signing or verification key.
+//
+// A key signs new tokens from ActiveFrom until RetireAt and keeps verifying
+// tokens until ExpiresAt, which should be at least one token lifetime after
+// RetireAt. Zero times mean "no limit". Keys without a PrivateKey are only
+// used for verification.
+type Key struct {
+	ID         string
+	Algorithm  string
+	PrivateKey crypto.PrivateKey
+	PublicKey  crypto.PublicKey
+	ActiveFrom time.Time
+	RetireAt   time.Time
+	ExpiresAt  time.Time
+}
+
+func (k *Key) canSign(now time.Time) bool {
+	return k.PrivateKey != nil &&
+		!now.Before(k.ActiveFrom) &&
+		(k.RetireAt.IsZero() || now.Before(k.RetireAt)) &&
+		k.canVerify(now)
+}
+
+func (k *Key) canVerify(now time.Time) bool {
+	return k.ExpiresAt.IsZero() || now.Before(k.ExpiresAt)
+}
+
+type KeyRing struct {
+	keys map[string]*Key
+	now  func() time.Time
+}
+
+// New builds a key ring from keys. Key IDs must be unique.
+func New(keys ...*Key) (*KeyRing, error) {
+	ring := &KeyRing{keys: make(map[string]*Key, len(keys)), now: time.Now}
+	for _, k := range keys {
+		if k.ID == "" {
+			return nil, errors.New("keyring: key ID is required")
+		}
+		if _, exists := ring.keys[k.ID]; exists {
+			return nil, fmt.Errorf("keyring: duplicate key ID %q", k.ID)
+		}
+		if err := validateKey(k); err != nil {
+			return nil, err
+		}
+		ring.keys[k.ID] = k
+	}
+	return ring, nil
+}
+
+// Sign signs claims with the current signing key and sets the "kid" header.
+func (r *KeyRing) Sign(claims jwt.Claims) (string, error) {
+	key := r.signingKey()
+	if key == nil {
+		return "", ErrNoSigningKey
+	}
+
+	token := jwt.NewWithClaims(jwt.GetSigningMethod(key.Algorithm), claims)
+	token.Header["kid"] = key.ID
+	return token.SignedString(key.PrivateKey)
+}
+
+// Keyfunc resolves the verification key for a token from its "kid" header.
+// It is meant to be passed to jwt.Parse.
+func (r *KeyRing) Keyfunc(token *jwt.Token) (interface{}, error) {
+	kid, _ := token.Header["kid"].(string)
+	key, ok := r.keys[kid]
+	if !ok || !key.canVerify(r.now()) {
+		return nil, fmt.Errorf("keyring: unknown or expired key %q", kid)
+	}
+
+	if token.Method.Alg() != key.Algorithm {
+		return nil, fmt.Errorf("keyring: unexpected signing method: %v", token.Header["alg"])
+	}
+
+	return key.PublicKey, nil
+}
+
+// JWKS returns the public keys that can still verify tokens, including keys
+// scheduled to become active later so verifiers can fetch them in advance.
+// Symmetric keys are never published.
+func (r *KeyRing) JWKS() JWKSet {
+	now := r.now()
+	set := JWKSet{Keys: []JWK{}}
+
+	for _, key := range r.sortedKeys() {
+		if !key.canVerify(now) {
+			continue
+		}
+		switch pub := key.PublicKey.(type) {
+		case *rsa.PublicKey:
+			set.Keys = append(set.Keys, JWK{
+				Kty: "RSA",
+				Kid: key.ID,
+				Use: "sig",
+				Alg: key.Algorithm,
+				N:   base64.RawURLEncoding.EncodeToString(pub.N.Bytes()),
+				E:   base64.RawURLEncoding.EncodeToString(big.NewInt(int64(pub.E)).Bytes()),
+			})
+		case ed25519.PublicKey:
+			set.Keys = append(set.Keys, JWK{
+				Kty: "OKP",
+				Kid: key.ID,
+				Use: "sig",
+				Alg: key.Algorithm,
+				Crv: "Ed25519",
+				X:   base64.RawURLEncoding.EncodeToString(pub),
+			})
+		}
+	}
+
+	return set
+}
+
+func (r *KeyRing) signingKey() *Key {
+	now := r.now()
+	var current *Key
+	for _, key := range r.sortedKeys() {
+		if key.canSign(now) && (current == nil || key.ActiveFrom.After(current.ActiveFrom)) {
+			current = key
+		}
+	}
+	return current
+}
+
+func (r *KeyRing) sortedKeys() []*Key {
+	keys := make([]*Key, 0, len(r.keys))
+	for _, key := range r.keys {
+		keys = append(keys, key)
+	}
+	sort.Slice(keys, func(i, j int) bool { return keys[i].ID < keys[j].ID })
+	return keys
+}
+
+func validateKey(k *Key) error {
+	var ok bool
+	switch k.Algorithm {
+	case AlgHS256:
+		_, ok = k.PublicKey.([]byte)
+	case AlgRS256:
+		_, ok = k.PublicKey.(*rsa.PublicKey)
+	case AlgEdDSA:
+		_, ok = k.PublicKey.(ed25519.PublicKey)
+	default:
+		return fmt.Errorf("keyring: unsupported algorithm %q for key %q", k.Algorithm, k.ID)
+	}
+	if !ok {
+		return fmt.Errorf("keyring: key %q does not match algorithm %s", k.ID, k.Algorithm)
+	}
+	return nil
+}
+
+// JWKSet is the JSON Web Key Set document served at /.well-known/jwks.json.
+type JWKSet struct {
+	Keys []JWK `json:"keys"`
+}
+
+type JWK struct {
+	Kty string `json:"kty"`
+	Kid string `json:"kid"`
+	Use string `json:"use"`
+	Alg string `json:"alg"`
+	N   string `json:"n,omitempty"`
+	E   string `json:"e,omitempty"`
+	Crv string `json:"crv,omitempty"`
+	X   string `json:"x,omitempty"`
+}
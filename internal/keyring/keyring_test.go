@@ -0,0 +1,102 @@
+package keyring
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/rsa"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+func newEdKey(t *testing.T, id string, activeFrom time.Time) *Key {
+	t.Helper()
+	pub, priv, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatalf("failed to generate key: %v", err)
+	}
+	return &Key{ID: id, Algorithm: AlgEdDSA, PrivateKey: priv, PublicKey: pub, ActiveFrom: activeFrom}
+}
+
+func kidOf(t *testing.T, ring *KeyRing, signed string) string {
+	t.Helper()
+	token, err := jwt.Parse(signed, ring.Keyfunc)
+	if err != nil || !token.Valid {
+		t.Fatalf("failed to verify token: %v", err)
+	}
+	return token.Header["kid"].(string)
+}
+
+func TestRotation(t *testing.T) {
+	now := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	oldKey := newEdKey(t, "old", now.Add(-24*time.Hour))
+	oldKey.RetireAt = now.Add(time.Hour)
+	oldKey.ExpiresAt = now.Add(2 * time.Hour)
+	newKey := newEdKey(t, "new", now.Add(time.Hour))
+
+	ring, err := New(oldKey, newKey)
+	if err != nil {
+		t.Fatalf("New returned error: %v", err)
+	}
+	ring.now = func() time.Time { return now }
+
+	claims := jwt.MapClaims{"sub": "user"}
+	beforeRotation, err := ring.Sign(claims)
+	if err != nil {
+		t.Fatalf("Sign returned error: %v", err)
+	}
+	if kid := kidOf(t, ring, beforeRotation); kid != "old" {
+		t.Errorf("expected token signed with old key before rotation; got %s", kid)
+	}
+	if n := len(ring.JWKS().Keys); n != 2 {
+		t.Errorf("expected both keys to be published ahead of rotation; got %d", n)
+	}
+
+	now = now.Add(90 * time.Minute)
+	afterRotation, err := ring.Sign(claims)
+	if err != nil {
+		t.Fatalf("Sign returned error: %v", err)
+	}
+	if kid := kidOf(t, ring, afterRotation); kid != "new" {
+		t.Errorf("expected token signed with new key after rotation; got %s", kid)
+	}
+	if kid := kidOf(t, ring, beforeRotation); kid != "old" {
+		t.Errorf("expected retired key to keep verifying; got %s", kid)
+	}
+
+	now = now.Add(time.Hour)
+	if _, err := jwt.Parse(beforeRotation, ring.Keyfunc); err == nil {
+		t.Error("expected token signed with expired key to be rejected")
+	}
+	if n := len(ring.JWKS().Keys); n != 1 {
+		t.Errorf("expected expired key to be dropped from JWKS; got %d keys", n)
+	}
+}
+
+func TestKeyfuncRejectsAlgorithmMismatch(t *testing.T) {
+	secret := []byte("secret")
+	hmacKey := &Key{ID: "hmac", Algorithm: AlgHS256, PrivateKey: secret, PublicKey: secret}
+	ring, err := New(hmacKey)
+	if err != nil {
+		t.Fatalf("New returned error: %v", err)
+	}
+
+	rsaKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatalf("failed to generate key: %v", err)
+	}
+	token := jwt.NewWithClaims(jwt.SigningMethodRS256, jwt.MapClaims{"sub": "user"})
+	token.Header["kid"] = "hmac"
+	signed, err := token.SignedString(rsaKey)
+	if err != nil {
+		t.Fatalf("failed to sign token: %v", err)
+	}
+
+	if _, err := jwt.Parse(signed, ring.Keyfunc); err == nil {
+		t.Error("expected token with mismatched algorithm to be rejected")
+	}
+	if n := len(ring.JWKS().Keys); n != 0 {
+		t.Errorf("expected symmetric keys to stay unpublished; got %d", n)
+	}
+}
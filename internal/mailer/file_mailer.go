@@ -0,0 +1,32 @@
+package mailer
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/pur108/talestoon-be/internal/domain"
+)
+
+// fileMailer writes every message to its own .eml file in dir so local
+// developers can open links from password reset and similar emails.
+type fileMailer struct {
+	dir string
+}
+
+func NewFileMailer(dir string) domain.Mailer {
+	return &fileMailer{dir}
+}
+
+func (m *fileMailer) Send(to, subject, body string) error {
+	if err := os.MkdirAll(m.dir, 0o755); err != nil {
+		return err
+	}
+
+	name := fmt.Sprintf("%s-%s.eml", time.Now().Format("20060102T150405"), uuid.NewString())
+	content := fmt.Sprintf("To: %s\r\nSubject: %s\r\nDate: %s\r\n\r\n%s\r\n", to, subject, time.Now().Format(time.RFC1123Z), body)
+
+	return os.WriteFile(filepath.Join(m.dir, name), []byte(content), 0o644)
+}
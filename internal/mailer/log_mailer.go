@@ -0,0 +1,20 @@
+package mailer
+
+import (
+	"log"
+
+	"github.com/pur108/talestoon-be/internal/domain"
+)
+
+// logMailer prints messages instead of sending them. Intended for local
+// development only.
+type logMailer struct{}
+
+func NewLogMailer() domain.Mailer {
+	return &logMailer{}
+}
+
+func (m *logMailer) Send(to, subject, body string) error {
+	log.Printf("mail to=%s subject=%q\n%s", to, subject, body)
+	return nil
+}
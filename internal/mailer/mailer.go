@@ -0,0 +1,22 @@
+package mailer
+
+import (
+	"os"
+
+	"github.com/pur108/talestoon-be/internal/domain"
+)
+
+// New returns the Mailer selected by MAIL_DRIVER. "file" writes each message
+// to MAIL_DIR (default "tmp/mail"); anything else logs messages to stdout.
+func New() domain.Mailer {
+	switch os.Getenv("MAIL_DRIVER") {
+	case "file":
+		dir := os.Getenv("MAIL_DIR")
+		if dir == "" {
+			dir = "tmp/mail"
+		}
+		return NewFileMailer(dir)
+	default:
+		return NewLogMailer()
+	}
+}
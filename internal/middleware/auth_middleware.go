@@ -1,54 +1,124 @@
 package middleware
 
 import (
-	"fmt"
-	"os"
+	"errors"
 	"strings"
 
 	"github.com/gofiber/fiber/v2"
-	"github.com/golang-jwt/jwt/v5"
 	"github.com/pur108/talestoon-be/internal/domain"
+	"github.com/pur108/talestoon-be/internal/usecase"
 )
 
-func Protected() fiber.Handler {
+const principalKey = "principal"
+
+// Protected rejects requests without a valid bearer token or API key, or from
+// suspended accounts, and stores the authenticated principal for
+// GetPrincipal.
+func Protected(authUsecase usecase.AuthUsecase) fiber.Handler {
 	return func(c *fiber.Ctx) error {
-		authHeader := c.Get("Authorization")
-		if authHeader == "" {
+		tokenString := bearerToken(c)
+		if tokenString == "" {
 			return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{"error": "Missing authorization header"})
 		}
 
-		tokenString := strings.Replace(authHeader, "Bearer ", "", 1)
-		token, err := jwt.Parse(tokenString, func(token *jwt.Token) (interface{}, error) {
-			if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
-				return nil, fmt.Errorf("unexpected signing method: %v", token.Header["alg"])
+		principal, err := authUsecase.Authenticate(tokenString)
+		if err != nil {
+			var suspended *domain.SuspendedError
+			if errors.As(err, &suspended) {
+				return c.Status(fiber.StatusForbidden).JSON(fiber.Map{
+					"error":  "Account " + string(suspended.Status),
+					"reason": suspended.Reason,
+					"until":  suspended.Until,
+				})
 			}
-			return []byte(os.Getenv("JWT_SECRET")), nil
-		})
-
-		if err != nil || !token.Valid {
 			return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{"error": "Invalid token"})
 		}
 
-		claims, ok := token.Claims.(jwt.MapClaims)
+		c.Locals(principalKey, principal)
+		return c.Next()
+	}
+}
+
+// OptionalAuth stores the principal when a valid bearer token is present and
+// otherwise lets the request through anonymously, so public routes can tailor
+// responses to logged-in users.
+func OptionalAuth(authUsecase usecase.AuthUsecase) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		if tokenString := bearerToken(c); tokenString != "" {
+			if principal, err := authUsecase.Authenticate(tokenString); err == nil {
+				c.Locals(principalKey, principal)
+			}
+		}
+		return c.Next()
+	}
+}
+
+// GetPrincipal returns the principal stored by Protected or OptionalAuth.
+func GetPrincipal(c *fiber.Ctx) (*domain.Principal, bool) {
+	principal, ok := c.Locals(principalKey).(*domain.Principal)
+	return principal, ok && principal != nil
+}
+
+func RoleRequired(roles ...domain.UserRole) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		principal, ok := GetPrincipal(c)
 		if !ok {
-			return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{"error": "Invalid token claims"})
+			return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{"error": "Unauthorized"})
+		}
+		if principal.HasRole(roles...) {
+			return c.Next()
 		}
+		return c.Status(fiber.StatusForbidden).JSON(fiber.Map{"error": "Insufficient permissions"})
+	}
+}
 
-		c.Locals("user_id", claims["user_id"])
-		c.Locals("role", claims["role"])
+// VerifiedRequired rejects users who have not verified their email address.
+// It must run after Protected.
+func VerifiedRequired() fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		principal, ok := GetPrincipal(c)
+		if !ok || !principal.EmailVerified {
+			return c.Status(fiber.StatusForbidden).JSON(fiber.Map{"error": "Email verification required"})
+		}
+		return c.Next()
+	}
+}
 
+// ScopeRequired rejects principals that were not granted scope. Interactive
+// sessions hold every scope; API keys only the ones chosen at creation.
+func ScopeRequired(scope string) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		principal, ok := GetPrincipal(c)
+		if !ok {
+			return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{"error": "Unauthorized"})
+		}
+		if !principal.HasScope(scope) {
+			return c.Status(fiber.StatusForbidden).JSON(fiber.Map{"error": "Missing scope " + scope})
+		}
 		return c.Next()
 	}
 }
 
-func RoleRequired(roles ...domain.UserRole) fiber.Handler {
+// SessionRequired rejects API key principals, keeping account management
+// available to interactive sessions only.
+func SessionRequired() fiber.Handler {
 	return func(c *fiber.Ctx) error {
-		userRole := c.Locals("role").(string)
-		for _, role := range roles {
-			if string(role) == userRole {
-				return c.Next()
-			}
+		principal, ok := GetPrincipal(c)
+		if !ok {
+			return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{"error": "Unauthorized"})
 		}
-		return c.Status(fiber.StatusForbidden).JSON(fiber.Map{"error": "Insufficient permissions"})
+		if principal.IsAPIKey() {
+			return c.Status(fiber.StatusForbidden).JSON(fiber.Map{"error": "API keys cannot access this resource"})
+		}
+		return c.Next()
+	}
+}
+
+// bearerToken returns the credential from the Authorization header, falling
+// back to the X-API-Key header.
+func bearerToken(c *fiber.Ctx) string {
+	if authHeader := c.Get(fiber.HeaderAuthorization); authHeader != "" {
+		return strings.TrimSpace(strings.TrimPrefix(authHeader, "Bearer "))
 	}
+	return strings.TrimSpace(c.Get("X-API-Key"))
 }
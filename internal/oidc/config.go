@@ -0,0 +1,51 @@
+package oidc
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+)
+
+// providerConfig is one entry of the JSON file named by OIDC_PROVIDERS_FILE.
+// The client secret is read from the environment variable in
+// client_secret_env so the file can be committed.
+type providerConfig struct {
+	Config
+	ClientSecretEnv string `json:"client_secret_env"`
+}
+
+// LoadProviders reads the configured identity providers. It returns an empty
+// map when OIDC_PROVIDERS_FILE is not set.
+func LoadProviders() (map[string]*Provider, error) {
+	providers := map[string]*Provider{}
+
+	path := os.Getenv("OIDC_PROVIDERS_FILE")
+	if path == "" {
+		return providers, nil
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var configs []providerConfig
+	if err := json.Unmarshal(data, &configs); err != nil {
+		return nil, fmt.Errorf("oidc: invalid %s: %w", path, err)
+	}
+
+	for _, cfg := range configs {
+		if cfg.Name == "" || cfg.Issuer == "" || cfg.ClientID == "" || cfg.RedirectURL == "" {
+			return nil, fmt.Errorf("oidc: provider %q is missing name, issuer, client_id or redirect_url", cfg.Name)
+		}
+		if _, exists := providers[cfg.Name]; exists {
+			return nil, fmt.Errorf("oidc: duplicate provider %q", cfg.Name)
+		}
+		if cfg.ClientSecretEnv != "" {
+			cfg.ClientSecret = os.Getenv(cfg.ClientSecretEnv)
+		}
+		providers[cfg.Name] = NewProvider(cfg.Config, nil)
+	}
+
+	return providers, nil
+}
@@ -0,0 +1,37 @@
+package repository
+
+import (
+	"github.com/pur108/talestoon-be/internal/domain"
+	"gorm.io/gorm"
+)
+
+type auditLogRepository struct {
+	db *gorm.DB
+}
+
+func NewAuditLogRepository(db *gorm.DB) domain.AuditLogRepository {
+	return &auditLogRepository{db}
+}
+
+func (r *auditLogRepository) Create(entry *domain.AdminAuditLog) error {
+	return r.db.Create(entry).Error
+}
+
+func (r *auditLogRepository) List(filter domain.AuditLogFilter) ([]domain.AdminAuditLog, error) {
+	var entries []domain.AdminAuditLog
+	query := r.db.Order("created_at desc").Limit(filter.Limit)
+	if filter.AdminID != nil {
+		query = query.Where("admin_id = ?", *filter.AdminID)
+	}
+	if filter.TargetUserID != nil {
+		query = query.Where("target_user_id = ?", *filter.TargetUserID)
+	}
+	if filter.Action != "" {
+		query = query.Where("action = ?", filter.Action)
+	}
+	err := query.Find(&entries).Error
+	if err != nil {
+		return nil, err
+	}
+	return entries, nil
+}
@@ -0,0 +1,98 @@
+package repository
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/pur108/talestoon-be/internal/domain"
+)
+
+// comicTitleExpr is the title comics are sorted by: English, or Thai when a
+// comic has no English title. It matches MultilingualText.In(LocaleEn).
+const comicTitleExpr = "COALESCE(NULLIF(comics.title->>'en', ''), comics.title->>'th', '')"
+
+// comicSortOrder describes how a ComicSort orders comics and how the sort
+// key of the last comic on a page is carried in the cursor. Ties are broken
+// by comic ID in the same direction.
+type comicSortOrder struct {
+	expr  string
+	desc  bool
+	key   func(comic *domain.Comic) interface{}
+	parse func(raw json.RawMessage) (interface{}, error)
+}
+
+var comicSortOrders = map[domain.ComicSort]comicSortOrder{
+	domain.SortUpdated: {
+		expr:  "comics.updated_at",
+		desc:  true,
+		key:   func(comic *domain.Comic) interface{} { return comic.UpdatedAt },
+		parse: parseCursorKey[time.Time],
+	},
+	domain.SortCreated: {
+		expr:  "comics.created_at",
+		desc:  true,
+		key:   func(comic *domain.Comic) interface{} { return comic.CreatedAt },
+		parse: parseCursorKey[time.Time],
+	},
+	domain.SortTitle: {
+		expr:  comicTitleExpr,
+		key:   func(comic *domain.Comic) interface{} { return comic.Title.In(domain.LocaleEn) },
+		parse: parseCursorKey[string],
+	},
+	domain.SortPopularity: {
+		expr:  "comics.follower_count",
+		desc:  true,
+		key:   func(comic *domain.Comic) interface{} { return comic.FollowerCount },
+		parse: parseCursorKey[int64],
+	},
+}
+
+// comicCursor is the decoded form of an opaque listing cursor. It records the
+// sort it was issued for so it cannot be replayed against another order.
+type comicCursor struct {
+	Sort domain.ComicSort `json:"s"`
+	Key  json.RawMessage  `json:"k"`
+	ID   uuid.UUID        `json:"id"`
+}
+
+func encodeComicCursor(sort domain.ComicSort, comic *domain.Comic) (string, error) {
+	key, err := json.Marshal(comicSortOrders[sort].key(comic))
+	if err != nil {
+		return "", err
+	}
+
+	raw, err := json.Marshal(comicCursor{Sort: sort, Key: key, ID: comic.ID})
+	if err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(raw), nil
+}
+
+// decodeComicCursor returns the sort key and comic ID to continue after.
+func decodeComicCursor(cursor string, sort domain.ComicSort) (interface{}, uuid.UUID, error) {
+	raw, err := base64.RawURLEncoding.DecodeString(cursor)
+	if err != nil {
+		return nil, uuid.Nil, domain.ErrInvalidCursor
+	}
+
+	var decoded comicCursor
+	if err := json.Unmarshal(raw, &decoded); err != nil || decoded.Sort != sort || decoded.ID == uuid.Nil {
+		return nil, uuid.Nil, domain.ErrInvalidCursor
+	}
+
+	key, err := comicSortOrders[sort].parse(decoded.Key)
+	if err != nil {
+		return nil, uuid.Nil, domain.ErrInvalidCursor
+	}
+	return key, decoded.ID, nil
+}
+
+func parseCursorKey[T any](raw json.RawMessage) (interface{}, error) {
+	var key T
+	if err := json.Unmarshal(raw, &key); err != nil {
+		return nil, err
+	}
+	return key, nil
+}
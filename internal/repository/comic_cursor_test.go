@@ -0,0 +1,64 @@
+package repository
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/pur108/talestoon-be/internal/domain"
+)
+
+func TestComicCursorRoundTrip(t *testing.T) {
+	comic := &domain.Comic{
+		ID:            uuid.New(),
+		Title:         domain.MultilingualText{Th: "เรื่องสั้น"},
+		FollowerCount: 42,
+		CreatedAt:     time.Date(2026, 3, 1, 8, 30, 0, 123456000, time.UTC),
+		UpdatedAt:     time.Date(2026, 4, 2, 9, 15, 0, 654321000, time.UTC),
+	}
+
+	want := map[domain.ComicSort]interface{}{
+		domain.SortUpdated:    comic.UpdatedAt,
+		domain.SortCreated:    comic.CreatedAt,
+		domain.SortTitle:      "เรื่องสั้น",
+		domain.SortPopularity: int64(42),
+	}
+
+	for sort, wantKey := range want {
+		cursor, err := encodeComicCursor(sort, comic)
+		if err != nil {
+			t.Fatalf("%s: encode returned error: %v", sort, err)
+		}
+
+		key, id, err := decodeComicCursor(cursor, sort)
+		if err != nil {
+			t.Fatalf("%s: decode returned error: %v", sort, err)
+		}
+		if id != comic.ID {
+			t.Errorf("%s: id = %s, want %s", sort, id, comic.ID)
+		}
+		if wantTime, ok := wantKey.(time.Time); ok {
+			if gotTime, _ := key.(time.Time); !gotTime.Equal(wantTime) {
+				t.Errorf("%s: key = %v, want %v", sort, key, wantKey)
+			}
+		} else if key != wantKey {
+			t.Errorf("%s: key = %v, want %v", sort, key, wantKey)
+		}
+	}
+}
+
+func TestComicCursorRejectsForeignSortAndGarbage(t *testing.T) {
+	comic := &domain.Comic{ID: uuid.New(), UpdatedAt: time.Now()}
+	cursor, err := encodeComicCursor(domain.SortUpdated, comic)
+	if err != nil {
+		t.Fatalf("encode returned error: %v", err)
+	}
+
+	if _, _, err := decodeComicCursor(cursor, domain.SortTitle); !errors.Is(err, domain.ErrInvalidCursor) {
+		t.Errorf("cursor for another sort: err = %v, want ErrInvalidCursor", err)
+	}
+	if _, _, err := decodeComicCursor("not a cursor!", domain.SortUpdated); !errors.Is(err, domain.ErrInvalidCursor) {
+		t.Errorf("garbage cursor: err = %v, want ErrInvalidCursor", err)
+	}
+}
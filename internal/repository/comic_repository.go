@@ -1,9 +1,14 @@
 package repository
 
 import (
+	"fmt"
+	"time"
+
 	"github.com/google/uuid"
+	"github.com/lib/pq"
 	"github.com/pur108/talestoon-be/internal/domain"
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
 type comicRepository struct {
@@ -14,8 +19,10 @@ func NewComicRepository(db *gorm.DB) domain.ComicRepository {
 	return &comicRepository{db}
 }
 
+// CreateComic inserts the comic and links its tags, which must already have
+// been resolved through TagRepository.FindOrCreate.
 func (r *comicRepository) CreateComic(comic *domain.Comic) error {
-	return r.db.Create(comic).Error
+	return r.db.Omit("Tags.*").Create(comic).Error
 }
 
 func (r *comicRepository) CreateChapter(chapter *domain.Chapter) error {
@@ -37,7 +44,9 @@ func (r *comicRepository) GetComicByID(id uuid.UUID) (*domain.Comic, error) {
 
 func (r *comicRepository) GetChapterByID(id uuid.UUID) (*domain.Chapter, error) {
 	var chapter domain.Chapter
-	err := r.db.Preload("Images.TextLayers.Translations").First(&chapter, id).Error
+	err := r.db.Preload("Images", func(db *gorm.DB) *gorm.DB {
+		return db.Order(`"order" asc`)
+	}).First(&chapter, id).Error
 	if err != nil {
 		return nil, err
 	}
@@ -53,13 +62,109 @@ func (r *comicRepository) GetSeasonByComicID(comicID uuid.UUID, seasonNumber int
 	return &season, nil
 }
 
-func (r *comicRepository) ListComics() ([]domain.Comic, error) {
+func (r *comicRepository) GetSeasonByID(id uuid.UUID) (*domain.Season, error) {
+	var season domain.Season
+	err := r.db.First(&season, id).Error
+	if err != nil {
+		return nil, err
+	}
+	return &season, nil
+}
+
+// ListComics returns one page of the public catalog: public, non-draft
+// comics by creators who are not banned. Pages are keyset-paginated on the
+// sort key and comic ID, so they stay stable while comics are added.
+func (r *comicRepository) ListComics(filter domain.ComicFilter) (*domain.ComicPage, error) {
+	if _, ok := comicSortOrders[filter.Sort]; !ok {
+		filter.Sort = domain.SortUpdated
+	}
+	order := comicSortOrders[filter.Sort]
+
+	var total int64
+	if err := publicComics(r.db, filter).Count(&total).Error; err != nil {
+		return nil, err
+	}
+
+	query := publicComics(r.db, filter)
+	if filter.Cursor != "" {
+		key, id, err := decodeComicCursor(filter.Cursor, filter.Sort)
+		if err != nil {
+			return nil, err
+		}
+		comparison := ">"
+		if order.desc {
+			comparison = "<"
+		}
+		query = query.Where(fmt.Sprintf("(%s, comics.id) %s (?, ?)", order.expr, comparison), key, id)
+	}
+
+	direction := "asc"
+	if order.desc {
+		direction = "desc"
+	}
+
+	// Fetch one extra row to learn whether another page follows.
 	var comics []domain.Comic
-	err := r.db.Preload("Tags.Translations").Order("updated_at desc").Limit(20).Find(&comics).Error
+	err := query.Preload("Tags.Translations").
+		Order(fmt.Sprintf("%s %s, comics.id %s", order.expr, direction, direction)).
+		Limit(filter.Limit + 1).
+		Find(&comics).Error
 	if err != nil {
 		return nil, err
 	}
-	return comics, nil
+
+	page := &domain.ComicPage{Comics: comics, Total: total}
+	if len(comics) > filter.Limit {
+		page.Comics = comics[:filter.Limit]
+		page.NextCursor, err = encodeComicCursor(filter.Sort, &page.Comics[filter.Limit-1])
+		if err != nil {
+			return nil, err
+		}
+	}
+	return page, nil
+}
+
+// publicComics builds the filtered catalog query shared by listings, search
+// and their total counts. Cursor, Sort and Limit are left to the caller.
+func publicComics(db *gorm.DB, filter domain.ComicFilter) *gorm.DB {
+	query := db.Model(&domain.Comic{}).
+		Where("comics.visibility = ? AND comics.status <> ?", domain.VisibilityPublic, domain.ComicDraft).
+		Where("comics.creator_id NOT IN (?)", bannedUserIDs(db))
+	if filter.ExcludeNSFW {
+		query = query.Where("comics.nsfw = ?", false)
+	}
+	if filter.NSFW != nil {
+		query = query.Where("comics.nsfw = ?", *filter.NSFW)
+	}
+	if len(filter.HiddenGenres) > 0 {
+		query = query.Where("NOT (COALESCE(comics.genres, '{}') && ?)", pq.StringArray(filter.HiddenGenres))
+	}
+	if len(filter.HiddenTags) > 0 {
+		query = query.Where("NOT EXISTS (SELECT 1 FROM comic_tags JOIN tags ON tags.id = comic_tags.tag_id WHERE comic_tags.comic_id = comics.id AND tags.slug IN ?)", filter.HiddenTags)
+	}
+	if filter.Genre != "" {
+		query = query.Where("? = ANY(comics.genres)", filter.Genre)
+	}
+	if filter.TagSlug != "" {
+		query = query.Where("EXISTS (SELECT 1 FROM comic_tags JOIN tags ON tags.id = comic_tags.tag_id WHERE comic_tags.comic_id = comics.id AND tags.slug = ?)", filter.TagSlug)
+	}
+	if filter.Status != "" {
+		query = query.Where("comics.status = ?", filter.Status)
+	}
+	if filter.Author != "" {
+		query = query.Where("LOWER(comics.author) = LOWER(?)", filter.Author)
+	}
+	if filter.CreatorID != nil {
+		query = query.Where("comics.creator_id = ?", *filter.CreatorID)
+	}
+	return query
+}
+
+// bannedUserIDs selects the users whose ban is in effect. Their comics are
+// left out of public listings.
+func bannedUserIDs(db *gorm.DB) *gorm.DB {
+	return db.Model(&domain.User{}).Select("id").
+		Where("status = ? AND (suspended_until IS NULL OR suspended_until > ?)", domain.UserBanned, time.Now())
 }
 
 func (r *comicRepository) ListComicsByCreatorID(creatorID uuid.UUID) ([]domain.Comic, error) {
@@ -80,10 +185,87 @@ func (r *comicRepository) ListComicsByAuthor(author string) ([]domain.Comic, err
 	return comics, nil
 }
 
-func (r *comicRepository) UpdateComic(comic *domain.Comic) error {
-	return r.db.Save(comic).Error
+// ListPublicComicsByCreatorID returns the creator's comics that appear in
+// public listings: public visibility and no longer a draft.
+func (r *comicRepository) ListPublicComicsByCreatorID(creatorID uuid.UUID) ([]domain.Comic, error) {
+	var comics []domain.Comic
+	err := r.db.Preload("Tags.Translations").
+		Where("creator_id = ? AND visibility = ? AND status <> ?", creatorID, domain.VisibilityPublic, domain.ComicDraft).
+		Order("updated_at desc").
+		Find(&comics).Error
+	if err != nil {
+		return nil, err
+	}
+	return comics, nil
+}
+
+// UpdateComic saves the comic's own fields and, when tags is not nil,
+// replaces its tags with them. The tags must already exist. Seasons are
+// changed through their own methods.
+func (r *comicRepository) UpdateComic(comic *domain.Comic, tags []domain.Tag) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Omit(clause.Associations).Save(comic).Error; err != nil {
+			return err
+		}
+		if tags == nil {
+			return nil
+		}
+
+		if err := tx.Exec("DELETE FROM comic_tags WHERE comic_id = ?", comic.ID).Error; err != nil {
+			return err
+		}
+		for _, tag := range tags {
+			if err := tx.Exec("INSERT INTO comic_tags (comic_id, tag_id) VALUES (?, ?) ON CONFLICT DO NOTHING", comic.ID, tag.ID).Error; err != nil {
+				return err
+			}
+		}
+		return nil
+	})
 }
 
 func (r *comicRepository) DeleteComic(id uuid.UUID) error {
-	return r.db.Delete(&domain.Comic{}, id).Error
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		return deleteComics(tx, tx.Model(&domain.Comic{}).Select("id").Where("id = ?", id))
+	})
+}
+
+// deleteComics deletes the comics selected by comicIDs and everything hanging
+// off them, children first so foreign keys are never violated.
+func deleteComics(tx *gorm.DB, comicIDs *gorm.DB) error {
+	seasonIDs := tx.Model(&domain.Season{}).Select("id").Where("comic_id IN (?)", comicIDs)
+	chapterIDs := tx.Model(&domain.Chapter{}).Select("id").Where("season_id IN (?)", seasonIDs)
+
+	if err := tx.Where("chapter_id IN (?)", chapterIDs).Delete(&domain.ChapterImage{}).Error; err != nil {
+		return err
+	}
+	if err := tx.Where("season_id IN (?)", seasonIDs).Delete(&domain.Chapter{}).Error; err != nil {
+		return err
+	}
+	if err := tx.Where("comic_id IN (?)", comicIDs).Delete(&domain.Season{}).Error; err != nil {
+		return err
+	}
+	if err := tx.Exec("DELETE FROM comic_tags WHERE comic_id IN (?)", comicIDs).Error; err != nil {
+		return err
+	}
+	if err := tx.Where("comic_id IN (?)", comicIDs).Delete(&domain.ComicSubscription{}).Error; err != nil {
+		return err
+	}
+	if err := tx.Where("type = ? AND (data->>'comic_id')::uuid IN (?)", domain.NotificationNewChapter, comicIDs).Delete(&domain.Notification{}).Error; err != nil {
+		return err
+	}
+	return tx.Where("id IN (?)", comicIDs).Delete(&domain.Comic{}).Error
+}
+
+// ListComicsWithChaptersByCreatorID loads the creator's comics with every
+// season, chapter and image, for data exports.
+func (r *comicRepository) ListComicsWithChaptersByCreatorID(creatorID uuid.UUID) ([]domain.Comic, error) {
+	var comics []domain.Comic
+	err := r.db.Preload("Tags.Translations").Preload("Seasons.Chapters.Images").
+		Where("creator_id = ?", creatorID).
+		Order("created_at asc").
+		Find(&comics).Error
+	if err != nil {
+		return nil, err
+	}
+	return comics, nil
 }
@@ -0,0 +1,122 @@
+package repository
+
+import (
+	"github.com/google/uuid"
+	"github.com/pur108/talestoon-be/internal/domain"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type followRepository struct {
+	db *gorm.DB
+}
+
+func NewFollowRepository(db *gorm.DB) domain.FollowRepository {
+	return &followRepository{db}
+}
+
+func (r *followRepository) FollowCreator(followerID, creatorID uuid.UUID) (bool, error) {
+	follow := &domain.CreatorFollow{FollowerID: followerID, CreatorID: creatorID}
+	return r.insertCounted(follow, "UPDATE users SET follower_count = follower_count + 1 WHERE id = ?", creatorID)
+}
+
+func (r *followRepository) UnfollowCreator(followerID, creatorID uuid.UUID) (bool, error) {
+	return r.deleteCounted(&domain.CreatorFollow{}, "UPDATE users SET follower_count = GREATEST(follower_count - 1, 0) WHERE id = ?", creatorID,
+		"follower_id = ? AND creator_id = ?", followerID, creatorID)
+}
+
+func (r *followRepository) Subscribe(userID, comicID uuid.UUID) (bool, error) {
+	subscription := &domain.ComicSubscription{UserID: userID, ComicID: comicID}
+	return r.insertCounted(subscription, "UPDATE comics SET follower_count = follower_count + 1 WHERE id = ?", comicID)
+}
+
+func (r *followRepository) Unsubscribe(userID, comicID uuid.UUID) (bool, error) {
+	return r.deleteCounted(&domain.ComicSubscription{}, "UPDATE comics SET follower_count = GREATEST(follower_count - 1, 0) WHERE id = ?", comicID,
+		"user_id = ? AND comic_id = ?", userID, comicID)
+}
+
+// insertCounted inserts row unless it already exists and bumps the matching
+// counter in the same transaction.
+func (r *followRepository) insertCounted(row interface{}, counterSQL string, id uuid.UUID) (bool, error) {
+	created := false
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		res := tx.Clauses(clause.OnConflict{DoNothing: true}).Create(row)
+		if res.Error != nil || res.RowsAffected == 0 {
+			return res.Error
+		}
+		created = true
+		return tx.Exec(counterSQL, id).Error
+	})
+	return created, err
+}
+
+// deleteCounted deletes the row matched by conds and lowers the matching
+// counter in the same transaction.
+func (r *followRepository) deleteCounted(model interface{}, counterSQL string, id uuid.UUID, conds ...interface{}) (bool, error) {
+	deleted := false
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		res := tx.Delete(model, conds...)
+		if res.Error != nil || res.RowsAffected == 0 {
+			return res.Error
+		}
+		deleted = true
+		return tx.Exec(counterSQL, id).Error
+	})
+	return deleted, err
+}
+
+func (r *followRepository) IsFollowing(followerID, creatorID uuid.UUID) (bool, error) {
+	var count int64
+	err := r.db.Model(&domain.CreatorFollow{}).Where("follower_id = ? AND creator_id = ?", followerID, creatorID).Count(&count).Error
+	return count > 0, err
+}
+
+func (r *followRepository) IsSubscribed(userID, comicID uuid.UUID) (bool, error) {
+	var count int64
+	err := r.db.Model(&domain.ComicSubscription{}).Where("user_id = ? AND comic_id = ?", userID, comicID).Count(&count).Error
+	return count > 0, err
+}
+
+// ListFollowedCreators returns the creators followerID follows, most recently
+// followed first. Banned and deleted accounts are left out.
+func (r *followRepository) ListFollowedCreators(followerID uuid.UUID) ([]domain.User, error) {
+	var users []domain.User
+	err := r.db.Joins("JOIN creator_follows ON creator_follows.creator_id = users.id").
+		Where("creator_follows.follower_id = ? AND users.anonymized_at IS NULL", followerID).
+		Where("users.id NOT IN (?)", bannedUserIDs(r.db)).
+		Order("creator_follows.created_at desc").
+		Find(&users).Error
+	if err != nil {
+		return nil, err
+	}
+	return users, nil
+}
+
+// ListSubscribedComics returns the public comics userID is subscribed to,
+// most recently updated first.
+func (r *followRepository) ListSubscribedComics(userID uuid.UUID) ([]domain.Comic, error) {
+	var comics []domain.Comic
+	err := r.db.Preload("Tags.Translations").
+		Joins("JOIN comic_subscriptions ON comic_subscriptions.comic_id = comics.id").
+		Where("comic_subscriptions.user_id = ?", userID).
+		Where("comics.visibility <> ? AND comics.status <> ?", domain.VisibilityPrivate, domain.ComicDraft).
+		Where("comics.creator_id NOT IN (?)", bannedUserIDs(r.db)).
+		Order("comics.updated_at desc").
+		Find(&comics).Error
+	if err != nil {
+		return nil, err
+	}
+	return comics, nil
+}
+
+func (r *followRepository) ListFollowerIDs(creatorID uuid.UUID) ([]uuid.UUID, error) {
+	var ids []uuid.UUID
+	err := r.db.Model(&domain.CreatorFollow{}).Where("creator_id = ?", creatorID).Pluck("follower_id", &ids).Error
+	return ids, err
+}
+
+func (r *followRepository) ListSubscriberIDs(comicID uuid.UUID) ([]uuid.UUID, error) {
+	var ids []uuid.UUID
+	err := r.db.Model(&domain.ComicSubscription{}).Where("comic_id = ?", comicID).Pluck("user_id", &ids).Error
+	return ids, err
+}
@@ -0,0 +1,78 @@
+package repository
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/pur108/talestoon-be/internal/domain"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type identityRepository struct {
+	db *gorm.DB
+}
+
+func NewIdentityRepository(db *gorm.DB) domain.IdentityRepository {
+	return &identityRepository{db}
+}
+
+func (r *identityRepository) Create(identity *domain.ExternalIdentity) error {
+	return r.db.Create(identity).Error
+}
+
+func (r *identityRepository) FindByProviderSubject(provider, subject string) (*domain.ExternalIdentity, error) {
+	var identity domain.ExternalIdentity
+	err := r.db.Where("provider = ? AND subject = ?", provider, subject).Take(&identity).Error
+	if err != nil {
+		return nil, err
+	}
+	return &identity, nil
+}
+
+func (r *identityRepository) ListByUserID(userID uuid.UUID) ([]domain.ExternalIdentity, error) {
+	var identities []domain.ExternalIdentity
+	err := r.db.Where("user_id = ?", userID).Order("created_at asc").Find(&identities).Error
+	if err != nil {
+		return nil, err
+	}
+	return identities, nil
+}
+
+func (r *identityRepository) Delete(id uuid.UUID, userID uuid.UUID) error {
+	res := r.db.Where("id = ? AND user_id = ?", id, userID).Delete(&domain.ExternalIdentity{})
+	if res.Error != nil {
+		return res.Error
+	}
+	if res.RowsAffected == 0 {
+		return domain.ErrNotFound
+	}
+	return nil
+}
+
+func (r *identityRepository) TouchLastLogin(id uuid.UUID, at time.Time) error {
+	return r.db.Model(&domain.ExternalIdentity{}).Where("id = ?", id).Update("last_login_at", at).Error
+}
+
+// CreateLoginState stores a pending authorization request and drops expired
+// ones, which are left behind whenever a user abandons the provider page.
+func (r *identityRepository) CreateLoginState(state *domain.OIDCLoginState) error {
+	if err := r.db.Where("expires_at < ?", time.Now()).Delete(&domain.OIDCLoginState{}).Error; err != nil {
+		return err
+	}
+	return r.db.Create(state).Error
+}
+
+// ConsumeLoginState deletes and returns the state in one statement so a
+// callback can only be completed once.
+func (r *identityRepository) ConsumeLoginState(stateHash string) (*domain.OIDCLoginState, error) {
+	var states []domain.OIDCLoginState
+	err := r.db.Clauses(clause.Returning{}).Where("state_hash = ?", stateHash).Delete(&states).Error
+	if err != nil {
+		return nil, err
+	}
+	if len(states) == 0 {
+		return nil, domain.ErrInvalidToken
+	}
+	return &states[0], nil
+}
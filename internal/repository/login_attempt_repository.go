@@ -0,0 +1,91 @@
+package repository
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/pur108/talestoon-be/internal/domain"
+	"gorm.io/gorm"
+)
+
+type loginAttemptRepository struct {
+	db *gorm.DB
+}
+
+func NewLoginAttemptRepository(db *gorm.DB) domain.LoginAttemptRepository {
+	return &loginAttemptRepository{db}
+}
+
+type failureStats struct {
+	Failures int64
+	LastAt   *time.Time
+}
+
+func (r *loginAttemptRepository) Create(attempt *domain.LoginAttempt) error {
+	return r.db.Create(attempt).Error
+}
+
+func (r *loginAttemptRepository) LastSuccessAt(identifier string) (*time.Time, error) {
+	var attempt domain.LoginAttempt
+	err := r.db.Where("identifier = ? AND success = ?", identifier, true).
+		Order("created_at desc").Take(&attempt).Error
+	if err == gorm.ErrRecordNotFound {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &attempt.CreatedAt, nil
+}
+
+// CountIdentifierFailures counts failed attempts for identifier since the
+// given time and returns when the most recent one happened. Throttled
+// attempts are not counted so an attacker cannot extend a lockout forever.
+func (r *loginAttemptRepository) CountIdentifierFailures(identifier string, since time.Time) (int64, *time.Time, error) {
+	return r.countFailures("identifier = ?", identifier, since)
+}
+
+func (r *loginAttemptRepository) CountIPFailures(ip string, since time.Time) (int64, *time.Time, error) {
+	return r.countFailures("ip = ?", ip, since)
+}
+
+func (r *loginAttemptRepository) countFailures(condition string, value string, since time.Time) (int64, *time.Time, error) {
+	var stats failureStats
+	err := r.db.Model(&domain.LoginAttempt{}).
+		Select("count(*) as failures, max(created_at) as last_at").
+		Where(condition, value).
+		Where("success = ? AND reason <> ? AND created_at > ?", false, domain.LoginReasonThrottled, since).
+		Scan(&stats).Error
+	if err != nil {
+		return 0, nil, err
+	}
+	return stats.Failures, stats.LastAt, nil
+}
+
+func (r *loginAttemptRepository) List(identifier, ip string, failedOnly bool, limit int) ([]domain.LoginAttempt, error) {
+	var attempts []domain.LoginAttempt
+	query := r.db.Order("created_at desc").Limit(limit)
+	if identifier != "" {
+		query = query.Where("identifier = ?", identifier)
+	}
+	if ip != "" {
+		query = query.Where("ip = ?", ip)
+	}
+	if failedOnly {
+		query = query.Where("success = ?", false)
+	}
+	err := query.Find(&attempts).Error
+	if err != nil {
+		return nil, err
+	}
+	return attempts, nil
+}
+
+func (r *loginAttemptRepository) ListByUserID(userID uuid.UUID, limit int) ([]domain.LoginAttempt, error) {
+	var attempts []domain.LoginAttempt
+	err := r.db.Where("user_id = ?", userID).Order("created_at desc").Limit(limit).Find(&attempts).Error
+	if err != nil {
+		return nil, err
+	}
+	return attempts, nil
+}
@@ -0,0 +1,179 @@
+package repository
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/pur108/talestoon-be/internal/domain"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+const notificationBatchSize = 500
+
+type notificationRepository struct {
+	db *gorm.DB
+}
+
+func NewNotificationRepository(db *gorm.DB) domain.NotificationRepository {
+	return &notificationRepository{db}
+}
+
+func (r *notificationRepository) CreateMany(notifications []domain.Notification) error {
+	if len(notifications) == 0 {
+		return nil
+	}
+	return r.db.CreateInBatches(notifications, notificationBatchSize).Error
+}
+
+// List pages on (created_at, id) so notifications created in the same
+// instant, such as a new chapter fanned out to its subscribers, are neither
+// skipped nor repeated at a page boundary.
+func (r *notificationRepository) List(userID uuid.UUID, filter domain.NotificationFilter) ([]domain.Notification, string, error) {
+	query := r.db.Where("user_id = ?", userID)
+	if filter.UnreadOnly {
+		query = query.Where("read_at IS NULL")
+	}
+	if filter.Cursor != "" {
+		cursor, err := decodeNotificationCursor(filter.Cursor)
+		if err != nil {
+			return nil, "", err
+		}
+		query = query.Where("(created_at, id) < (?, ?)", cursor.CreatedAt, cursor.ID)
+	}
+
+	// Fetch one extra row to learn whether another page follows.
+	var notifications []domain.Notification
+	err := query.Order("created_at desc, id desc").Limit(filter.Limit + 1).Find(&notifications).Error
+	if err != nil {
+		return nil, "", err
+	}
+
+	if len(notifications) <= filter.Limit {
+		return notifications, "", nil
+	}
+	notifications = notifications[:filter.Limit]
+	next, err := encodeNotificationCursor(&notifications[filter.Limit-1])
+	if err != nil {
+		return nil, "", err
+	}
+	return notifications, next, nil
+}
+
+func (r *notificationRepository) ListByUserID(userID uuid.UUID) ([]domain.Notification, error) {
+	var notifications []domain.Notification
+	err := r.db.Where("user_id = ?", userID).Order("created_at desc").Find(&notifications).Error
+	if err != nil {
+		return nil, err
+	}
+	return notifications, nil
+}
+
+func (r *notificationRepository) CountUnread(userID uuid.UUID) (int64, error) {
+	var count int64
+	err := r.db.Model(&domain.Notification{}).Where("user_id = ? AND read_at IS NULL", userID).Count(&count).Error
+	return count, err
+}
+
+func (r *notificationRepository) MarkRead(userID uuid.UUID, ids []uuid.UUID, at time.Time) (int64, error) {
+	res := r.db.Model(&domain.Notification{}).
+		Where("user_id = ? AND id IN ? AND read_at IS NULL", userID, ids).
+		Update("read_at", at)
+	return res.RowsAffected, res.Error
+}
+
+func (r *notificationRepository) MarkAllRead(userID uuid.UUID, at time.Time) (int64, error) {
+	res := r.db.Model(&domain.Notification{}).
+		Where("user_id = ? AND read_at IS NULL", userID).
+		Update("read_at", at)
+	return res.RowsAffected, res.Error
+}
+
+func (r *notificationRepository) ListPreferences(userID uuid.UUID) ([]domain.NotificationPreference, error) {
+	var preferences []domain.NotificationPreference
+	err := r.db.Where("user_id = ?", userID).Find(&preferences).Error
+	if err != nil {
+		return nil, err
+	}
+	return preferences, nil
+}
+
+func (r *notificationRepository) SavePreferences(preferences []domain.NotificationPreference) error {
+	if len(preferences) == 0 {
+		return nil
+	}
+	return r.db.Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "user_id"}, {Name: "type"}},
+		DoUpdates: clause.AssignmentColumns([]string{"enabled"}),
+	}).Create(&preferences).Error
+}
+
+func (r *notificationRepository) FilterRecipients(userIDs []uuid.UUID, notificationType domain.NotificationType) ([]uuid.UUID, error) {
+	if len(userIDs) == 0 {
+		return nil, nil
+	}
+
+	// Query in chunks to stay well below the Postgres bind parameter limit
+	// for comics with many subscribers.
+	skip := make(map[uuid.UUID]bool)
+	for start := 0; start < len(userIDs); start += notificationBatchSize {
+		end := min(start+notificationBatchSize, len(userIDs))
+
+		var disabled []uuid.UUID
+		err := r.db.Model(&domain.NotificationPreference{}).
+			Where("user_id IN ? AND type = ? AND enabled = ?", userIDs[start:end], notificationType, false).
+			Pluck("user_id", &disabled).Error
+		if err != nil {
+			return nil, err
+		}
+		for _, id := range disabled {
+			skip[id] = true
+		}
+	}
+
+	recipients := make([]uuid.UUID, 0, len(userIDs))
+	for _, id := range userIDs {
+		if !skip[id] {
+			recipients = append(recipients, id)
+		}
+	}
+	return recipients, nil
+}
+
+func (r *notificationRepository) HasFollowerNotification(creatorID, followerID uuid.UUID) (bool, error) {
+	var count int64
+	err := r.db.Model(&domain.Notification{}).
+		Where("user_id = ? AND type = ? AND data->>'follower_id' = ?", creatorID, domain.NotificationNewFollower, followerID.String()).
+		Count(&count).Error
+	return count > 0, err
+}
+
+// notificationCursor is the decoded form of an opaque notification cursor:
+// the position of the last notification on the previous page.
+type notificationCursor struct {
+	CreatedAt time.Time `json:"t"`
+	ID        uuid.UUID `json:"id"`
+}
+
+func encodeNotificationCursor(notification *domain.Notification) (string, error) {
+	raw, err := json.Marshal(notificationCursor{CreatedAt: notification.CreatedAt, ID: notification.ID})
+	if err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(raw), nil
+}
+
+func decodeNotificationCursor(cursor string) (*notificationCursor, error) {
+	raw, err := base64.RawURLEncoding.DecodeString(cursor)
+	if err != nil {
+		return nil, domain.ErrInvalidCursor
+	}
+
+	var decoded notificationCursor
+	if err := json.Unmarshal(raw, &decoded); err != nil || decoded.ID == uuid.Nil || decoded.CreatedAt.IsZero() {
+		return nil, domain.ErrInvalidCursor
+	}
+	return &decoded, nil
+}
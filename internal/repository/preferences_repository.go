@@ -0,0 +1,35 @@
+package repository
+
+import (
+	"errors"
+
+	"github.com/google/uuid"
+	"github.com/pur108/talestoon-be/internal/domain"
+	"gorm.io/gorm"
+)
+
+type preferencesRepository struct {
+	db *gorm.DB
+}
+
+func NewPreferencesRepository(db *gorm.DB) domain.PreferencesRepository {
+	return &preferencesRepository{db}
+}
+
+// FindByUserID returns the stored preferences, or the defaults when the user
+// never saved any.
+func (r *preferencesRepository) FindByUserID(userID uuid.UUID) (*domain.UserPreferences, error) {
+	var preferences domain.UserPreferences
+	err := r.db.Where("user_id = ?", userID).Take(&preferences).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return domain.DefaultPreferences(userID), nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &preferences, nil
+}
+
+func (r *preferencesRepository) Save(preferences *domain.UserPreferences) error {
+	return r.db.Save(preferences).Error
+}
@@ -0,0 +1,114 @@
+package repository
+
+import (
+	"github.com/google/uuid"
+	"github.com/pur108/talestoon-be/internal/domain"
+	"gorm.io/gorm"
+)
+
+type roleRepository struct {
+	db *gorm.DB
+}
+
+func NewRoleRepository(db *gorm.DB) domain.RoleRepository {
+	return &roleRepository{db}
+}
+
+func (r *roleRepository) CreateRequest(request *domain.RoleRequest) error {
+	return r.db.Create(request).Error
+}
+
+func (r *roleRepository) FindRequestByID(id uuid.UUID) (*domain.RoleRequest, error) {
+	var request domain.RoleRequest
+	err := r.db.First(&request, id).Error
+	if err != nil {
+		return nil, err
+	}
+	return &request, nil
+}
+
+func (r *roleRepository) FindPendingRequestByUserID(userID uuid.UUID) (*domain.RoleRequest, error) {
+	var request domain.RoleRequest
+	err := r.db.Where("user_id = ? AND status = ?", userID, domain.RoleRequestPending).Take(&request).Error
+	if err != nil {
+		return nil, err
+	}
+	return &request, nil
+}
+
+func (r *roleRepository) ListRequests(status domain.RoleRequestStatus) ([]domain.RoleRequest, error) {
+	var requests []domain.RoleRequest
+	query := r.db.Order("created_at asc")
+	if status != "" {
+		query = query.Where("status = ?", status)
+	}
+	err := query.Find(&requests).Error
+	if err != nil {
+		return nil, err
+	}
+	return requests, nil
+}
+
+func (r *roleRepository) ListRequestsByUserID(userID uuid.UUID) ([]domain.RoleRequest, error) {
+	var requests []domain.RoleRequest
+	err := r.db.Where("user_id = ?", userID).Order("created_at desc").Find(&requests).Error
+	if err != nil {
+		return nil, err
+	}
+	return requests, nil
+}
+
+// ReviewRequest only updates the request while it is still pending, so
+// concurrent reviews cannot both succeed or apply the role change twice.
+func (r *roleRepository) ReviewRequest(request *domain.RoleRequest, change *domain.RoleChange) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		res := tx.Model(&domain.RoleRequest{}).
+			Where("id = ? AND status = ?", request.ID, domain.RoleRequestPending).
+			Updates(map[string]interface{}{
+				"status":      request.Status,
+				"review_note": request.ReviewNote,
+				"reviewed_by": request.ReviewedBy,
+				"reviewed_at": request.ReviewedAt,
+				"updated_at":  request.UpdatedAt,
+			})
+		if res.Error != nil {
+			return res.Error
+		}
+		if res.RowsAffected == 0 {
+			return domain.ErrRequestReviewed
+		}
+
+		if change == nil {
+			return nil
+		}
+		return applyRoleChange(tx, change)
+	})
+}
+
+// ApplyRoleChange updates the user's role and records the change in the same
+// transaction so the audit trail never drifts from the users table.
+func (r *roleRepository) ApplyRoleChange(change *domain.RoleChange) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		return applyRoleChange(tx, change)
+	})
+}
+
+func applyRoleChange(tx *gorm.DB, change *domain.RoleChange) error {
+	res := tx.Model(&domain.User{}).Where("id = ?", change.UserID).Update("role", change.NewRole)
+	if res.Error != nil {
+		return res.Error
+	}
+	if res.RowsAffected == 0 {
+		return domain.ErrNotFound
+	}
+	return tx.Create(change).Error
+}
+
+func (r *roleRepository) ListRoleChanges(userID uuid.UUID) ([]domain.RoleChange, error) {
+	var changes []domain.RoleChange
+	err := r.db.Where("user_id = ?", userID).Order("created_at desc").Find(&changes).Error
+	if err != nil {
+		return nil, err
+	}
+	return changes, nil
+}
@@ -0,0 +1,141 @@
+package repository
+
+import (
+	"strings"
+
+	"github.com/pur108/talestoon-be/internal/domain"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// Search combines two strategies. Postgres full-text search with the
+// 'simple' configuration handles space-separated words in either language,
+// while substring and trigram matching cover Thai, which is written without
+// spaces between words and so is not split into useful lexemes.
+//
+// The expressions below must match the trigram indexes created in
+// database.createSearchIndexes, or those indexes will not be used.
+const (
+	searchTitlesExpr = "COALESCE(comics.title->>'en', '') || ' ' || COALESCE(comics.title->>'th', '')"
+	searchSecondExpr = "COALESCE(comics.subtitle->>'en', '') || ' ' || COALESCE(comics.subtitle->>'th', '') || ' ' || COALESCE(comics.author, '')"
+	searchDescExpr   = "COALESCE(comics.description->>'en', '') || ' ' || COALESCE(comics.description->>'th', '')"
+	searchTextExpr   = searchTitlesExpr + " || ' ' || " + searchSecondExpr + " || ' ' || " + searchDescExpr
+
+	searchVectorExpr = "setweight(to_tsvector('simple', " + searchTitlesExpr + "), 'A') || " +
+		"setweight(to_tsvector('simple', " + searchSecondExpr + "), 'B') || " +
+		"setweight(to_tsvector('simple', " + searchDescExpr + "), 'C')"
+
+	searchTagMatchExpr = "EXISTS (SELECT 1 FROM comic_tags JOIN tag_translations ON tag_translations.tag_id = comic_tags.tag_id " +
+		"WHERE comic_tags.comic_id = comics.id AND tag_translations.name ILIKE ?)"
+
+	// searchPrefixTitleExpr and searchPrefixThTitleExpr match the
+	// text_pattern_ops indexes so LIKE 'prefix%' can use them.
+	searchPrefixTitleExpr   = "LOWER(comics.title->>'en')"
+	searchPrefixThTitleExpr = "LOWER(comics.title->>'th')"
+
+	// searchTitleSimilarity is the word_similarity above which a title
+	// counts as a fuzzy match, to tolerate typos.
+	searchTitleSimilarity = 0.5
+)
+
+type searchRepository struct {
+	db *gorm.DB
+}
+
+func NewSearchRepository(db *gorm.DB) domain.SearchRepository {
+	return &searchRepository{db}
+}
+
+// SearchComics returns public comics matching query, most relevant first.
+// Title matches rank above subtitle, author and tag matches, which rank above
+// description matches.
+func (r *searchRepository) SearchComics(query string, filter domain.ComicFilter, offset int) ([]domain.Comic, int64, error) {
+	pattern := "%" + escapeLike(query) + "%"
+	lowered := strings.ToLower(query)
+
+	matches := func(db *gorm.DB) *gorm.DB {
+		return db.Where(
+			"(("+searchVectorExpr+") @@ plainto_tsquery('simple', ?) OR ("+searchTextExpr+") ILIKE ? OR "+searchTagMatchExpr+" OR word_similarity(?, "+searchTitlesExpr+") > ?)",
+			lowered, pattern, pattern, lowered, searchTitleSimilarity,
+		)
+	}
+
+	var total int64
+	if err := matches(publicComics(r.db, filter)).Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	rank := clause.OrderBy{Expression: clause.Expr{
+		SQL: "ts_rank(" + searchVectorExpr + ", plainto_tsquery('simple', ?)) + word_similarity(?, " + searchTitlesExpr + ") + " +
+			"CASE WHEN (" + searchTitlesExpr + ") ILIKE ? THEN 1 ELSE 0 END + CASE WHEN " + searchTagMatchExpr + " THEN 0.5 ELSE 0 END DESC, comics.id",
+		Vars: []interface{}{lowered, lowered, pattern, pattern},
+	}}
+
+	var comics []domain.Comic
+	err := matches(publicComics(r.db, filter)).
+		Preload("Tags.Translations").
+		Order(rank).
+		Offset(offset).
+		Limit(filter.Limit).
+		Find(&comics).Error
+	if err != nil {
+		return nil, 0, err
+	}
+	return comics, total, nil
+}
+
+// SuggestComics returns public comics whose English or Thai title starts with
+// prefix, most followed first. Only the fields a suggestion shows are loaded.
+func (r *searchRepository) SuggestComics(prefix string, filter domain.ComicFilter) ([]domain.Comic, error) {
+	pattern := escapeLike(prefix) + "%"
+
+	var comics []domain.Comic
+	err := publicComics(r.db, filter).
+		Select("comics.id", "comics.title", "comics.cover_image_url", "comics.follower_count").
+		Where("("+searchPrefixTitleExpr+" LIKE ? OR "+searchPrefixThTitleExpr+" LIKE ?)", pattern, pattern).
+		Order("comics.follower_count desc, comics.id").
+		Limit(filter.Limit).
+		Find(&comics).Error
+	if err != nil {
+		return nil, err
+	}
+	return comics, nil
+}
+
+// SuggestAuthors returns distinct author names starting with prefix among
+// public comics.
+func (r *searchRepository) SuggestAuthors(prefix string, filter domain.ComicFilter) ([]string, error) {
+	var authors []string
+	err := publicComics(r.db, filter).
+		Distinct("comics.author").
+		Where("LOWER(comics.author) LIKE ?", escapeLike(prefix)+"%").
+		Order("comics.author").
+		Limit(filter.Limit).
+		Pluck("comics.author", &authors).Error
+	if err != nil {
+		return nil, err
+	}
+	return authors, nil
+}
+
+// SuggestTags returns tags that have a name starting with prefix in any
+// language, with all their translations. Only tags on comics the reader can
+// browse are suggested, and tags the reader hid are left out.
+func (r *searchRepository) SuggestTags(prefix string, filter domain.ComicFilter) ([]domain.Tag, error) {
+	query := r.db.Preload("Translations").
+		Where("EXISTS (SELECT 1 FROM tag_translations WHERE tag_translations.tag_id = tags.id AND LOWER(tag_translations.name) LIKE ?)", escapeLike(prefix)+"%").
+		Where("EXISTS (SELECT 1 FROM comic_tags WHERE comic_tags.tag_id = tags.id AND comic_tags.comic_id IN (?))", publicComics(r.db, filter).Select("comics.id"))
+	if len(filter.HiddenTags) > 0 {
+		query = query.Where("tags.slug NOT IN ?", filter.HiddenTags)
+	}
+
+	var tags []domain.Tag
+	err := query.
+		Order("tags.slug").
+		Limit(filter.Limit).
+		Find(&tags).Error
+	if err != nil {
+		return nil, err
+	}
+	return tags, nil
+}
@@ -0,0 +1,239 @@
+package repository
+
+import (
+	"errors"
+	"strings"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/pur108/talestoon-be/internal/domain"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type tagRepository struct {
+	db *gorm.DB
+}
+
+func NewTagRepository(db *gorm.DB) domain.TagRepository {
+	return &tagRepository{db}
+}
+
+// FindOrCreate relies on the unique slug and (tag_id, language) indexes, so
+// concurrent requests using the same new tag converge on one row instead of
+// failing.
+func (r *tagRepository) FindOrCreate(tags []domain.Tag) ([]domain.Tag, error) {
+	if len(tags) == 0 {
+		return []domain.Tag{}, nil
+	}
+
+	slugs := make([]string, 0, len(tags))
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		for _, tag := range tags {
+			slugs = append(slugs, tag.Slug)
+
+			candidate := domain.Tag{ID: uuid.New(), Slug: tag.Slug}
+			err := tx.Omit("Translations").
+				Clauses(clause.OnConflict{Columns: []clause.Column{{Name: "slug"}}, DoNothing: true}).
+				Create(&candidate).Error
+			if err != nil {
+				return err
+			}
+
+			var stored domain.Tag
+			if err := tx.Where("slug = ?", tag.Slug).Take(&stored).Error; err != nil {
+				return err
+			}
+
+			if err := mergeTagTranslations(tx, stored.ID, tag.Translations); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	var resolved []domain.Tag
+	err = r.db.Preload("Translations").Where("slug IN ?", slugs).Find(&resolved).Error
+	if err != nil {
+		return nil, err
+	}
+	return resolved, nil
+}
+
+// mergeTagTranslations adds translations in new languages to a tag and fills
+// in existing ones that are empty, leaving names that are already set alone.
+func mergeTagTranslations(tx *gorm.DB, tagID uuid.UUID, translations []domain.TagTranslation) error {
+	for _, translation := range translations {
+		if translation.Name == "" {
+			continue
+		}
+
+		row := domain.TagTranslation{ID: uuid.New(), TagID: tagID, Language: translation.Language, Name: translation.Name}
+		err := tx.Clauses(clause.OnConflict{
+			Columns: []clause.Column{{Name: "tag_id"}, {Name: "language"}},
+			DoUpdates: clause.Assignments(map[string]interface{}{
+				"name": gorm.Expr("CASE WHEN tag_translations.name = '' THEN excluded.name ELSE tag_translations.name END"),
+			}),
+		}).Create(&row).Error
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// withComicCounts selects tags together with how many public comics use each.
+func withComicCounts(db *gorm.DB) *gorm.DB {
+	counts := publicComics(db, domain.ComicFilter{}).
+		Select("comic_tags.tag_id, COUNT(*) AS comic_count").
+		Joins("JOIN comic_tags ON comic_tags.comic_id = comics.id").
+		Group("comic_tags.tag_id")
+	return db.Model(&domain.Tag{}).
+		Select("tags.*, COALESCE(counts.comic_count, 0) AS comic_count").
+		Joins("LEFT JOIN (?) AS counts ON counts.tag_id = tags.id", counts)
+}
+
+func (r *tagRepository) List(filter domain.TagFilter) ([]domain.Tag, int64, error) {
+	matching := func() *gorm.DB {
+		query := withComicCounts(r.db)
+		if filter.Query != "" {
+			pattern := "%" + escapeLike(strings.ToLower(filter.Query)) + "%"
+			query = query.Where("tags.slug LIKE ? OR EXISTS (SELECT 1 FROM tag_translations WHERE tag_translations.tag_id = tags.id AND LOWER(tag_translations.name) LIKE ?)", pattern, pattern)
+		}
+		if filter.InUse {
+			query = query.Where("counts.comic_count > 0")
+		}
+		return query
+	}
+
+	var total int64
+	if err := matching().Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	order := "comic_count desc, tags.slug asc"
+	if filter.Sort == domain.TagSortName {
+		order = "tags.slug asc"
+	}
+
+	var tags []domain.Tag
+	err := matching().Preload("Translations").Order(order).Limit(filter.Limit).Offset(filter.Offset).Find(&tags).Error
+	if err != nil {
+		return nil, 0, err
+	}
+	return tags, total, nil
+}
+
+func (r *tagRepository) FindByID(id uuid.UUID) (*domain.Tag, error) {
+	var tag domain.Tag
+	err := withComicCounts(r.db).Preload("Translations").Where("tags.id = ?", id).Take(&tag).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, domain.ErrNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &tag, nil
+}
+
+func (r *tagRepository) FindBySlug(slug string) (*domain.Tag, error) {
+	var tag domain.Tag
+	err := withComicCounts(r.db).Preload("Translations").Where("tags.slug = ?", slug).Take(&tag).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, domain.ErrNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &tag, nil
+}
+
+// UpdateSlug renames a tag and carries the new slug over to the readers who
+// hid it.
+func (r *tagRepository) UpdateSlug(id uuid.UUID, slug string) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		var tag domain.Tag
+		if err := tx.Take(&tag, "id = ?", id).Error; err != nil {
+			return err
+		}
+		if err := tx.Model(&tag).Update("slug", slug).Error; err != nil {
+			if isUniqueViolation(err) {
+				return domain.ErrAlreadyExists
+			}
+			return err
+		}
+		return replaceHiddenTag(tx, tag.Slug, slug)
+	})
+}
+
+// isUniqueViolation reports whether err is a Postgres unique_violation.
+func isUniqueViolation(err error) bool {
+	var pgErr *pgconn.PgError
+	return errors.As(err, &pgErr) && pgErr.Code == "23505"
+}
+
+// SaveTranslation sets the tag's name in language, adding the translation
+// when the tag has none in that language yet.
+func (r *tagRepository) SaveTranslation(tagID uuid.UUID, language, name string) error {
+	row := domain.TagTranslation{ID: uuid.New(), TagID: tagID, Language: language, Name: name}
+	return r.db.Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "tag_id"}, {Name: "language"}},
+		DoUpdates: clause.AssignmentColumns([]string{"name"}),
+	}).Create(&row).Error
+}
+
+func (r *tagRepository) Merge(sourceID, targetID uuid.UUID) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		var source, target domain.Tag
+		if err := tx.Preload("Translations").Take(&source, "id = ?", sourceID).Error; err != nil {
+			return err
+		}
+		if err := tx.Take(&target, "id = ?", targetID).Error; err != nil {
+			return err
+		}
+
+		if err := tx.Exec("INSERT INTO comic_tags (comic_id, tag_id) SELECT comic_id, ? FROM comic_tags WHERE tag_id = ? ON CONFLICT DO NOTHING", targetID, sourceID).Error; err != nil {
+			return err
+		}
+		if err := mergeTagTranslations(tx, targetID, source.Translations); err != nil {
+			return err
+		}
+		if err := replaceHiddenTag(tx, source.Slug, target.Slug); err != nil {
+			return err
+		}
+		return deleteTag(tx, sourceID)
+	})
+}
+
+// Delete removes the tag from every comic and from readers' hidden tags.
+func (r *tagRepository) Delete(id uuid.UUID) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		var tag domain.Tag
+		if err := tx.Take(&tag, "id = ?", id).Error; err != nil {
+			return err
+		}
+		if err := tx.Exec("UPDATE user_preferences SET hidden_tags = array_remove(hidden_tags, ?) WHERE ? = ANY(hidden_tags)", tag.Slug, tag.Slug).Error; err != nil {
+			return err
+		}
+		return deleteTag(tx, id)
+	})
+}
+
+func deleteTag(tx *gorm.DB, id uuid.UUID) error {
+	if err := tx.Exec("DELETE FROM comic_tags WHERE tag_id = ?", id).Error; err != nil {
+		return err
+	}
+	if err := tx.Where("tag_id = ?", id).Delete(&domain.TagTranslation{}).Error; err != nil {
+		return err
+	}
+	return tx.Where("id = ?", id).Delete(&domain.Tag{}).Error
+}
+
+// replaceHiddenTag swaps oldSlug for newSlug in readers' hidden tags without
+// leaving duplicates behind.
+func replaceHiddenTag(tx *gorm.DB, oldSlug, newSlug string) error {
+	return tx.Exec("UPDATE user_preferences SET hidden_tags = ARRAY(SELECT DISTINCT unnest(array_replace(hidden_tags, ?, ?))) WHERE ? = ANY(hidden_tags)", oldSlug, newSlug, oldSlug).Error
+}
@@ -0,0 +1,160 @@
+package repository
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/pur108/talestoon-be/internal/domain"
+	"gorm.io/gorm"
+)
+
+type tokenRepository struct {
+	db *gorm.DB
+}
+
+func NewTokenRepository(db *gorm.DB) domain.TokenRepository {
+	return &tokenRepository{db}
+}
+
+func (r *tokenRepository) CreateRefreshToken(token *domain.RefreshToken) error {
+	return r.db.Create(token).Error
+}
+
+func (r *tokenRepository) FindRefreshTokenByHash(hash string) (*domain.RefreshToken, error) {
+	var token domain.RefreshToken
+	err := r.db.Where("token_hash = ?", hash).Take(&token).Error
+	if err != nil {
+		return nil, err
+	}
+	return &token, nil
+}
+
+// RotateRefreshToken revokes the old token and stores its replacement in a
+// single transaction. It fails with domain.ErrInvalidToken if the old token
+// was already used, so concurrent refreshes cannot both succeed.
+func (r *tokenRepository) RotateRefreshToken(oldID uuid.UUID, next *domain.RefreshToken) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		res := tx.Model(&domain.RefreshToken{}).
+			Where("id = ? AND revoked_at IS NULL", oldID).
+			Updates(map[string]interface{}{"revoked_at": time.Now(), "replaced_by_id": next.ID})
+		if res.Error != nil {
+			return res.Error
+		}
+		if res.RowsAffected == 0 {
+			return domain.ErrInvalidToken
+		}
+		return tx.Create(next).Error
+	})
+}
+
+func (r *tokenRepository) RevokeSessionRefreshTokens(sessionID uuid.UUID) error {
+	return r.db.Model(&domain.RefreshToken{}).
+		Where("session_id = ? AND revoked_at IS NULL", sessionID).
+		Update("revoked_at", time.Now()).Error
+}
+
+func (r *tokenRepository) RevokeUserRefreshTokens(userID uuid.UUID) error {
+	return r.db.Model(&domain.RefreshToken{}).
+		Where("user_id = ? AND revoked_at IS NULL", userID).
+		Update("revoked_at", time.Now()).Error
+}
+
+func (r *tokenRepository) RevokeAccessToken(token *domain.RevokedToken) error {
+	// Entries for tokens that have expired on their own are no longer needed.
+	if err := r.db.Where("expires_at < ?", time.Now()).Delete(&domain.RevokedToken{}).Error; err != nil {
+		return err
+	}
+	return r.db.Create(token).Error
+}
+
+func (r *tokenRepository) IsAccessTokenRevoked(jti string) (bool, error) {
+	var count int64
+	err := r.db.Model(&domain.RevokedToken{}).Where("jti = ?", jti).Count(&count).Error
+	return count > 0, err
+}
+
+func (r *tokenRepository) CreatePasswordResetToken(token *domain.PasswordResetToken) error {
+	return r.db.Create(token).Error
+}
+
+func (r *tokenRepository) FindPasswordResetTokenByHash(hash string) (*domain.PasswordResetToken, error) {
+	var token domain.PasswordResetToken
+	err := r.db.Where("token_hash = ?", hash).Take(&token).Error
+	if err != nil {
+		return nil, err
+	}
+	return &token, nil
+}
+
+// ConsumePasswordResetToken marks a reset token as used. It fails with
+// domain.ErrInvalidToken if the token was already used.
+func (r *tokenRepository) ConsumePasswordResetToken(id uuid.UUID) error {
+	res := r.db.Model(&domain.PasswordResetToken{}).
+		Where("id = ? AND used_at IS NULL", id).
+		Update("used_at", time.Now())
+	if res.Error != nil {
+		return res.Error
+	}
+	if res.RowsAffected == 0 {
+		return domain.ErrInvalidToken
+	}
+	return nil
+}
+
+func (r *tokenRepository) InvalidateUserPasswordResetTokens(userID uuid.UUID) error {
+	return r.db.Model(&domain.PasswordResetToken{}).
+		Where("user_id = ? AND used_at IS NULL", userID).
+		Update("used_at", time.Now()).Error
+}
+
+func (r *tokenRepository) CreateEmailVerificationToken(token *domain.EmailVerificationToken) error {
+	return r.db.Create(token).Error
+}
+
+func (r *tokenRepository) FindEmailVerificationTokenByHash(hash string) (*domain.EmailVerificationToken, error) {
+	var token domain.EmailVerificationToken
+	err := r.db.Where("token_hash = ?", hash).Take(&token).Error
+	if err != nil {
+		return nil, err
+	}
+	return &token, nil
+}
+
+func (r *tokenRepository) ConsumeEmailVerificationToken(id uuid.UUID) error {
+	res := r.db.Model(&domain.EmailVerificationToken{}).
+		Where("id = ? AND used_at IS NULL", id).
+		Update("used_at", time.Now())
+	if res.Error != nil {
+		return res.Error
+	}
+	if res.RowsAffected == 0 {
+		return domain.ErrInvalidToken
+	}
+	return nil
+}
+
+// ReplaceRecoveryCodes discards a user's existing recovery codes and stores
+// the new set.
+func (r *tokenRepository) ReplaceRecoveryCodes(userID uuid.UUID, codes []domain.RecoveryCode) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("user_id = ?", userID).Delete(&domain.RecoveryCode{}).Error; err != nil {
+			return err
+		}
+		return tx.Create(&codes).Error
+	})
+}
+
+// ConsumeRecoveryCode marks a matching unused recovery code as used. It
+// fails with domain.ErrInvalidToken if no such code exists.
+func (r *tokenRepository) ConsumeRecoveryCode(userID uuid.UUID, hash string) error {
+	res := r.db.Model(&domain.RecoveryCode{}).
+		Where("user_id = ? AND code_hash = ? AND used_at IS NULL", userID, hash).
+		Update("used_at", time.Now())
+	if res.Error != nil {
+		return res.Error
+	}
+	if res.RowsAffected == 0 {
+		return domain.ErrInvalidToken
+	}
+	return nil
+}
@@ -1,6 +1,9 @@
 package repository
 
 import (
+	"strings"
+	"time"
+
 	"github.com/google/uuid"
 	"github.com/pur108/talestoon-be/internal/domain"
 	"gorm.io/gorm"
@@ -24,7 +27,7 @@ func (r *userRepository) Update(user *domain.User) error {
 
 func (r *userRepository) FindByEmailOrUsername(identifier string) (*domain.User, error) {
 	var user domain.User
-	err := r.db.Select("id, role, password_hash").
+	err := r.db.Select("id, role, password_hash, two_factor_enabled_at, password_reset_required, status, suspension_reason, suspended_until").
 		Where("email = ?", identifier).
 		Or("username = ?", identifier).
 		Take(&user).Error
@@ -42,3 +45,261 @@ func (r *userRepository) FindByID(id uuid.UUID) (*domain.User, error) {
 	}
 	return &user, nil
 }
+
+func (r *userRepository) FindByEmail(email string) (*domain.User, error) {
+	var user domain.User
+	err := r.db.Where("email = ?", email).Take(&user).Error
+	if err != nil {
+		return nil, err
+	}
+	return &user, nil
+}
+
+func (r *userRepository) FindByUsername(username string) (*domain.User, error) {
+	var user domain.User
+	err := r.db.Where("username = ?", username).Take(&user).Error
+	if err != nil {
+		return nil, err
+	}
+	return &user, nil
+}
+
+func (r *userRepository) UpdateProfile(user *domain.User) error {
+	return r.db.Model(user).Select("display_name", "avatar_url", "bio", "links").Updates(user).Error
+}
+
+// UpdatePassword sets a new password hash, which also satisfies a forced
+// password reset.
+func (r *userRepository) UpdatePassword(id uuid.UUID, passwordHash string) error {
+	return r.db.Model(&domain.User{}).Where("id = ?", id).
+		Updates(map[string]interface{}{"password_hash": passwordHash, "password_reset_required": false}).Error
+}
+
+func (r *userRepository) SetTokensRevokedAt(id uuid.UUID, at time.Time) error {
+	return r.db.Model(&domain.User{}).Where("id = ?", id).Update("tokens_revoked_at", at).Error
+}
+
+func (r *userRepository) MarkEmailVerified(id uuid.UUID, at time.Time) error {
+	return r.db.Model(&domain.User{}).Where("id = ? AND email_verified_at IS NULL", id).Update("email_verified_at", at).Error
+}
+
+func (r *userRepository) SetTwoFactorSecret(id uuid.UUID, secret string) error {
+	return r.db.Model(&domain.User{}).Where("id = ?", id).Update("two_factor_secret", secret).Error
+}
+
+func (r *userRepository) EnableTwoFactor(id uuid.UUID, at time.Time, codes []domain.RecoveryCode) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("user_id = ?", id).Delete(&domain.RecoveryCode{}).Error; err != nil {
+			return err
+		}
+		if err := tx.Create(&codes).Error; err != nil {
+			return err
+		}
+		return tx.Model(&domain.User{}).Where("id = ?", id).Update("two_factor_enabled_at", at).Error
+	})
+}
+
+func (r *userRepository) DisableTwoFactor(id uuid.UUID) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		err := tx.Model(&domain.User{}).Where("id = ?", id).
+			Updates(map[string]interface{}{"two_factor_secret": "", "two_factor_enabled_at": nil, "two_factor_last_step": 0}).Error
+		if err != nil {
+			return err
+		}
+		return tx.Where("user_id = ?", id).Delete(&domain.RecoveryCode{}).Error
+	})
+}
+
+// UseTwoFactorStep advances the last accepted TOTP step. The condition makes
+// concurrent attempts with the same code race for a single row update.
+func (r *userRepository) UseTwoFactorStep(id uuid.UUID, step int64) error {
+	res := r.db.Model(&domain.User{}).Where("id = ? AND two_factor_last_step < ?", id, step).Update("two_factor_last_step", step)
+	if res.Error != nil {
+		return res.Error
+	}
+	if res.RowsAffected == 0 {
+		return domain.ErrInvalidToken
+	}
+	return nil
+}
+
+func (r *userRepository) SetDeletionScheduledAt(id uuid.UUID, at *time.Time) error {
+	return r.db.Model(&domain.User{}).Where("id = ? AND anonymized_at IS NULL", id).Update("deletion_scheduled_at", at).Error
+}
+
+func (r *userRepository) ListDueDeletions(before time.Time, exclude []uuid.UUID, limit int) ([]domain.User, error) {
+	query := r.db.Where("deletion_scheduled_at <= ? AND anonymized_at IS NULL", before)
+	if len(exclude) > 0 {
+		query = query.Where("id NOT IN ?", exclude)
+	}
+
+	var users []domain.User
+	err := query.
+		Order("deletion_scheduled_at asc").
+		Limit(limit).
+		Find(&users).Error
+	if err != nil {
+		return nil, err
+	}
+	return users, nil
+}
+
+// removeFollows deletes every follow and subscription made by or of the user
+// and corrects the follower counts they contributed to.
+func removeFollows(tx *gorm.DB, id uuid.UUID) error {
+	if err := tx.Exec("UPDATE users SET follower_count = GREATEST(follower_count - 1, 0) WHERE id IN (SELECT creator_id FROM creator_follows WHERE follower_id = ?)", id).Error; err != nil {
+		return err
+	}
+	if err := tx.Exec("UPDATE comics SET follower_count = GREATEST(follower_count - 1, 0) WHERE id IN (SELECT comic_id FROM comic_subscriptions WHERE user_id = ?)", id).Error; err != nil {
+		return err
+	}
+	if err := tx.Exec("UPDATE users SET follower_count = 0 WHERE id = ?", id).Error; err != nil {
+		return err
+	}
+	if err := tx.Exec("DELETE FROM creator_follows WHERE follower_id = ? OR creator_id = ?", id, id).Error; err != nil {
+		return err
+	}
+	return tx.Exec("DELETE FROM comic_subscriptions WHERE user_id = ?", id).Error
+}
+
+// Anonymize scrubs the personal data of a deleted account in one transaction.
+// The user row is kept with placeholder values so role history and other
+// records that reference it stay valid; the user's comics, credentials and
+// sessions are removed and login audit records lose their identifying fields.
+func (r *userRepository) Anonymize(id uuid.UUID, at time.Time) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		placeholder := "deleted_" + strings.ReplaceAll(id.String(), "-", "")
+		res := tx.Model(&domain.User{}).Where("id = ?", id).Updates(map[string]interface{}{
+			"username":              placeholder,
+			"email":                 placeholder + "@deleted.invalid",
+			"password_hash":         "",
+			"role":                  domain.RoleUser,
+			"display_name":          "",
+			"avatar_url":            "",
+			"bio":                   nil,
+			"links":                 nil,
+			"two_factor_secret":     "",
+			"two_factor_enabled_at": nil,
+			"birth_date":            nil,
+			"age_confirmed_at":      nil,
+			"tokens_revoked_at":     at,
+			"deletion_scheduled_at": nil,
+			"anonymized_at":         at,
+		})
+		if res.Error != nil {
+			return res.Error
+		}
+		if res.RowsAffected == 0 {
+			return domain.ErrNotFound
+		}
+
+		for _, model := range []interface{}{
+			&domain.RefreshToken{},
+			&domain.Session{},
+			&domain.APIKey{},
+			&domain.ExternalIdentity{},
+			&domain.RecoveryCode{},
+			&domain.PasswordResetToken{},
+			&domain.EmailVerificationToken{},
+			&domain.UserPreferences{},
+			&domain.Notification{},
+			&domain.NotificationPreference{},
+		} {
+			if err := tx.Where("user_id = ?", id).Delete(model).Error; err != nil {
+				return err
+			}
+		}
+
+		if err := tx.Where("link_user_id = ?", id).Delete(&domain.OIDCLoginState{}).Error; err != nil {
+			return err
+		}
+
+		// Other users' notifications about this user name them.
+		if err := tx.Where("type = ? AND data->>'follower_id' = ?", domain.NotificationNewFollower, id.String()).Delete(&domain.Notification{}).Error; err != nil {
+			return err
+		}
+
+		if err := deleteComics(tx, tx.Model(&domain.Comic{}).Select("id").Where("creator_id = ?", id)); err != nil {
+			return err
+		}
+
+		if err := removeFollows(tx, id); err != nil {
+			return err
+		}
+
+		return tx.Model(&domain.LoginAttempt{}).Where("user_id = ?", id).
+			Updates(map[string]interface{}{"identifier": "", "ip": "", "user_agent": ""}).Error
+	})
+}
+
+func (r *userRepository) Search(filter domain.UserFilter) ([]domain.User, int64, error) {
+	query := r.db.Model(&domain.User{}).Where("anonymized_at IS NULL")
+	if filter.Query != "" {
+		pattern := "%" + escapeLike(strings.ToLower(filter.Query)) + "%"
+		query = query.Where("LOWER(username) LIKE ? OR LOWER(email) LIKE ? OR LOWER(display_name) LIKE ?", pattern, pattern, pattern)
+	}
+	if filter.Role != "" {
+		query = query.Where("role = ?", filter.Role)
+	}
+	if filter.Status != "" {
+		query = query.Where("status = ?", filter.Status)
+	}
+
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	var users []domain.User
+	err := query.Order("created_at desc").Limit(filter.Limit).Offset(filter.Offset).Find(&users).Error
+	if err != nil {
+		return nil, 0, err
+	}
+	return users, total, nil
+}
+
+func (r *userRepository) SetStatus(id uuid.UUID, status domain.UserStatus, reason string, until *time.Time) error {
+	res := r.db.Model(&domain.User{}).Where("id = ?", id).Updates(map[string]interface{}{
+		"status":            status,
+		"suspension_reason": reason,
+		"suspended_until":   until,
+	})
+	if res.Error != nil {
+		return res.Error
+	}
+	if res.RowsAffected == 0 {
+		return domain.ErrNotFound
+	}
+	return nil
+}
+
+func (r *userRepository) SetPasswordResetRequired(id uuid.UUID, required bool) error {
+	return r.db.Model(&domain.User{}).Where("id = ?", id).Update("password_reset_required", required).Error
+}
+
+// SetBirthDate records a birth date. It only succeeds once per account.
+func (r *userRepository) SetBirthDate(id uuid.UUID, birthDate time.Time, at time.Time) error {
+	res := r.db.Model(&domain.User{}).Where("id = ? AND birth_date IS NULL", id).
+		Updates(map[string]interface{}{"birth_date": birthDate, "age_confirmed_at": at})
+	if res.Error != nil {
+		return res.Error
+	}
+	if res.RowsAffected == 0 {
+		return domain.ErrNotFound
+	}
+	return nil
+}
+
+// escapeLike escapes the LIKE wildcards in s so it is matched literally.
+func escapeLike(s string) string {
+	return strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`).Replace(s)
+}
+
+// LiftExpiredRestrictions reactivates accounts whose suspension or ban has
+// run out.
+func (r *userRepository) LiftExpiredRestrictions(now time.Time) (int64, error) {
+	res := r.db.Model(&domain.User{}).
+		Where("status <> ? AND suspended_until IS NOT NULL AND suspended_until <= ?", domain.UserActive, now).
+		Updates(map[string]interface{}{"status": domain.UserActive, "suspension_reason": "", "suspended_until": nil})
+	return res.RowsAffected, res.Error
+}
@@ -0,0 +1,25 @@
+package server
+
+import (
+	"log"
+	"time"
+)
+
+// startJob runs job every interval in the background for the lifetime of the
+// process, logging failures and how many items each run handled.
+func startJob(name string, interval time.Duration, job func() (int, error)) {
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		for range ticker.C {
+			n, err := job()
+			if err != nil {
+				log.Printf("%s job failed: %v", name, err)
+			}
+			if n > 0 {
+				log.Printf("%s job processed %d item(s)", name, n)
+			}
+		}
+	}()
+}
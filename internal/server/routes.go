@@ -1,11 +1,17 @@
 package server
 
 import (
+	"log"
+	"time"
+
 	"github.com/gofiber/fiber/v2"
 	"github.com/gofiber/fiber/v2/middleware/cors"
 	"github.com/gofiber/fiber/v2/middleware/logger"
 
 	"github.com/pur108/talestoon-be/internal/delivery/http"
+	"github.com/pur108/talestoon-be/internal/keyring"
+	"github.com/pur108/talestoon-be/internal/mailer"
+	"github.com/pur108/talestoon-be/internal/oidc"
 	"github.com/pur108/talestoon-be/internal/repository"
 	"github.com/pur108/talestoon-be/internal/usecase"
 )
@@ -15,7 +21,7 @@ func (s *FiberServer) RegisterFiberRoutes() {
 	s.App.Use(cors.New(cors.Config{
 		AllowOrigins:     "*",
 		AllowMethods:     "GET,POST,PUT,DELETE,OPTIONS,PATCH",
-		AllowHeaders:     "Accept,Authorization,Content-Type",
+		AllowHeaders:     "Accept,Authorization,Content-Type,X-API-Key",
 		AllowCredentials: false, // credentials require explicit origins
 		MaxAge:           300,
 	}))
@@ -25,19 +31,79 @@ func (s *FiberServer) RegisterFiberRoutes() {
 
 	db := s.db.GetDB()
 
-	// user routes
 	userRepo := repository.NewUserRepository(db)
-	userUsecase := usecase.NewUserUsecase(userRepo)
-	http.NewUserHandler(s.App, userUsecase)
+	tokenRepo := repository.NewTokenRepository(db)
+	sessionRepo := repository.NewSessionRepository(db)
+	loginAttemptRepo := repository.NewLoginAttemptRepository(db)
+	apiKeyRepo := repository.NewAPIKeyRepository(db)
+
+	keys, err := keyring.Load()
+	if err != nil {
+		log.Fatal(err)
+	}
 
 	// auth routes
-	authUsecase := usecase.NewAuthUsecase(userRepo)
+	mail := mailer.New()
+	authUsecase := usecase.NewAuthUsecase(userRepo, tokenRepo, sessionRepo, loginAttemptRepo, apiKeyRepo, mail, keys)
 	http.NewAuthHandler(s.App, authUsecase)
+	http.NewJWKSHandler(s.App, keys)
+	twoFactorUsecase := usecase.NewTwoFactorUsecase(userRepo, tokenRepo)
+	http.NewTwoFactorHandler(s.App, twoFactorUsecase, authUsecase)
+
+	providers, err := oidc.LoadProviders()
+	if err != nil {
+		log.Fatal(err)
+	}
+	identityRepo := repository.NewIdentityRepository(db)
+	oidcUsecase := usecase.NewOIDCUsecase(identityRepo, userRepo, authUsecase, providers)
+	http.NewOIDCHandler(s.App, oidcUsecase, authUsecase)
+
+	// notification routes
+	notificationRepo := repository.NewNotificationRepository(db)
+	notificationUsecase := usecase.NewNotificationUsecase(notificationRepo)
+	http.NewNotificationHandler(s.App, notificationUsecase, authUsecase)
+
+	// user routes
+	roleRepo := repository.NewRoleRepository(db)
+	roleUsecase := usecase.NewRoleUsecase(roleRepo, userRepo, notificationUsecase)
+	preferencesRepo := repository.NewPreferencesRepository(db)
+	userUsecase := usecase.NewUserUsecase(userRepo, preferencesRepo)
+	sessionUsecase := usecase.NewSessionUsecase(sessionRepo, tokenRepo)
+	http.NewUserHandler(s.App, userUsecase, roleUsecase, sessionUsecase, authUsecase)
+	auditLogRepo := repository.NewAuditLogRepository(db)
+	adminUsecase := usecase.NewAdminUsecase(userRepo, auditLogRepo, sessionRepo, roleUsecase, authUsecase)
+	http.NewAdminHandler(s.App, adminUsecase, roleUsecase, authUsecase)
+	startJob("suspension expiry", 5*time.Minute, adminUsecase.LiftExpiredRestrictions)
 
 	// comic routes
 	comicRepo := repository.NewComicRepository(db)
-	comicUsecase := usecase.NewComicUsecase(comicRepo, userRepo)
-	http.NewComicHandler(s.App, comicUsecase)
+	tagRepo := repository.NewTagRepository(db)
+	followRepo := repository.NewFollowRepository(db)
+	comicUsecase := usecase.NewComicUsecase(comicRepo, tagRepo, userRepo, preferencesRepo, followRepo, notificationUsecase)
+	http.NewComicHandler(s.App, comicUsecase, authUsecase)
+	http.NewUploadHandler(s.App, authUsecase)
+
+	// tag routes
+	tagUsecase := usecase.NewTagUsecase(tagRepo, comicUsecase)
+	http.NewTagHandler(s.App, tagUsecase, authUsecase)
+
+	// search routes
+	searchRepo := repository.NewSearchRepository(db)
+	searchUsecase := usecase.NewSearchUsecase(searchRepo, userRepo, preferencesRepo)
+	http.NewSearchHandler(s.App, searchUsecase, authUsecase)
+
+	// follow routes
+	followUsecase := usecase.NewFollowUsecase(followRepo, userRepo, comicRepo, notificationRepo, notificationUsecase)
+	http.NewFollowHandler(s.App, followUsecase, authUsecase)
+
+	// api key routes
+	apiKeyUsecase := usecase.NewAPIKeyUsecase(apiKeyRepo)
+	http.NewAPIKeyHandler(s.App, apiKeyUsecase, authUsecase)
+
+	// account export and deletion
+	accountUsecase := usecase.NewAccountUsecase(userRepo, tokenRepo, comicRepo, roleRepo, sessionRepo, loginAttemptRepo, apiKeyRepo, identityRepo, preferencesRepo, followRepo, notificationRepo, mail)
+	http.NewAccountHandler(s.App, accountUsecase, authUsecase)
+	startJob("account purge", time.Hour, accountUsecase.PurgeDueDeletions)
 
 	//auto migration
 }
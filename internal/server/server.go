@@ -1,6 +1,8 @@
 package server
 
 import (
+	"os"
+
 	"github.com/gofiber/fiber/v2"
 
 	"github.com/pur108/talestoon-be/internal/database"
@@ -17,6 +19,9 @@ func New() *FiberServer {
 		App: fiber.New(fiber.Config{
 			ServerHeader: "talestoon",
 			AppName:      "talestoon",
+			// Set when running behind a reverse proxy so c.IP() reports the
+			// client address used for login throttling.
+			ProxyHeader: os.Getenv("PROXY_HEADER"),
 		}),
 
 		db: database.New(),
@@ -0,0 +1,197 @@
+package usecase
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/pur108/talestoon-be/internal/domain"
+	"golang.org/x/crypto/bcrypt"
+)
+
+const (
+	// accountDeletionGrace is how long a deletion request can be cancelled
+	// before the account is anonymized.
+	accountDeletionGrace = 30 * 24 * time.Hour
+
+	exportLoginAttemptLimit = 1000
+	purgeBatchSize          = 50
+)
+
+// AccountExport bundles everything stored about a user for a data request.
+type AccountExport struct {
+	ExportedAt    time.Time                 `json:"exported_at"`
+	Profile       *domain.User              `json:"profile"`
+	Comics        []domain.Comic            `json:"comics"`
+	RoleRequests  []domain.RoleRequest      `json:"role_requests"`
+	RoleChanges   []domain.RoleChange       `json:"role_changes"`
+	Sessions      []domain.Session          `json:"sessions"`
+	LoginAttempts []domain.LoginAttempt     `json:"login_attempts"`
+	APIKeys       []domain.APIKey           `json:"api_keys"`
+	Identities    []domain.ExternalIdentity `json:"identities"`
+	Following     []*domain.PublicProfile   `json:"following"`
+	Subscriptions []domain.Comic            `json:"subscriptions"`
+	// Notifications holds every stored notification, read or not.
+	Notifications           []domain.Notification           `json:"notifications"`
+	NotificationPreferences []domain.NotificationPreference `json:"notification_preferences"`
+}
+
+type AccountUsecase interface {
+	Export(userID uuid.UUID) (*AccountExport, error)
+	RequestDeletion(userID uuid.UUID, password, code string) (time.Time, error)
+	CancelDeletion(userID uuid.UUID) error
+	PurgeDueDeletions() (int, error)
+}
+
+type accountUsecase struct {
+	userRepo         domain.UserRepository
+	tokenRepo        domain.TokenRepository
+	comicRepo        domain.ComicRepository
+	roleRepo         domain.RoleRepository
+	sessionRepo      domain.SessionRepository
+	loginAttemptRepo domain.LoginAttemptRepository
+	apiKeyRepo       domain.APIKeyRepository
+	identityRepo     domain.IdentityRepository
+	preferencesRepo  domain.PreferencesRepository
+	followRepo       domain.FollowRepository
+	notificationRepo domain.NotificationRepository
+	mailer           domain.Mailer
+}
+
+func NewAccountUsecase(userRepo domain.UserRepository, tokenRepo domain.TokenRepository, comicRepo domain.ComicRepository, roleRepo domain.RoleRepository, sessionRepo domain.SessionRepository, loginAttemptRepo domain.LoginAttemptRepository, apiKeyRepo domain.APIKeyRepository, identityRepo domain.IdentityRepository, preferencesRepo domain.PreferencesRepository, followRepo domain.FollowRepository, notificationRepo domain.NotificationRepository, mailer domain.Mailer) AccountUsecase {
+	return &accountUsecase{userRepo, tokenRepo, comicRepo, roleRepo, sessionRepo, loginAttemptRepo, apiKeyRepo, identityRepo, preferencesRepo, followRepo, notificationRepo, mailer}
+}
+
+func (u *accountUsecase) Export(userID uuid.UUID) (*AccountExport, error) {
+	user, err := u.userRepo.FindByID(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	export := &AccountExport{ExportedAt: time.Now(), Profile: user}
+
+	if user.Preferences, err = u.preferencesRepo.FindByUserID(userID); err != nil {
+		return nil, err
+	}
+
+	if export.Comics, err = u.comicRepo.ListComicsWithChaptersByCreatorID(userID); err != nil {
+		return nil, err
+	}
+	if export.RoleRequests, err = u.roleRepo.ListRequestsByUserID(userID); err != nil {
+		return nil, err
+	}
+	if export.RoleChanges, err = u.roleRepo.ListRoleChanges(userID); err != nil {
+		return nil, err
+	}
+	if export.Sessions, err = u.sessionRepo.ListByUserID(userID); err != nil {
+		return nil, err
+	}
+	if export.LoginAttempts, err = u.loginAttemptRepo.ListByUserID(userID, exportLoginAttemptLimit); err != nil {
+		return nil, err
+	}
+	if export.APIKeys, err = u.apiKeyRepo.ListByUserID(userID); err != nil {
+		return nil, err
+	}
+	if export.Identities, err = u.identityRepo.ListByUserID(userID); err != nil {
+		return nil, err
+	}
+	creators, err := u.followRepo.ListFollowedCreators(userID)
+	if err != nil {
+		return nil, err
+	}
+	export.Following = make([]*domain.PublicProfile, 0, len(creators))
+	for i := range creators {
+		export.Following = append(export.Following, creators[i].PublicProfile())
+	}
+	if export.Subscriptions, err = u.followRepo.ListSubscribedComics(userID); err != nil {
+		return nil, err
+	}
+	if export.Notifications, err = u.notificationRepo.ListByUserID(userID); err != nil {
+		return nil, err
+	}
+	if export.NotificationPreferences, err = u.notificationRepo.ListPreferences(userID); err != nil {
+		return nil, err
+	}
+
+	return export, nil
+}
+
+// RequestDeletion schedules the account for deletion after the grace period.
+// The password, and the second factor when enabled, must be confirmed; accounts
+// created through an identity provider have no password to confirm.
+func (u *accountUsecase) RequestDeletion(userID uuid.UUID, password, code string) (time.Time, error) {
+	user, err := u.userRepo.FindByID(userID)
+	if err != nil {
+		return time.Time{}, err
+	}
+
+	if user.DeletionScheduledAt != nil {
+		return *user.DeletionScheduledAt, nil
+	}
+
+	if user.PasswordHash != "" {
+		if err := bcrypt.CompareHashAndPassword([]byte(user.PasswordHash), []byte(password)); err != nil {
+			return time.Time{}, domain.ErrInvalidCredentials
+		}
+	}
+
+	if user.TwoFactorEnabledAt != nil {
+		if err := verifySecondFactor(u.userRepo, u.tokenRepo, user, code); err != nil {
+			return time.Time{}, err
+		}
+	}
+
+	scheduledAt := time.Now().Add(accountDeletionGrace)
+	if err := u.userRepo.SetDeletionScheduledAt(userID, &scheduledAt); err != nil {
+		return time.Time{}, err
+	}
+
+	body := fmt.Sprintf("Hi %s,\n\nYour Talestoon account is scheduled for deletion on %s. Your comics will be removed and your personal data erased.\n\nChanged your mind? Log in and cancel the deletion before then.", user.Username, scheduledAt.Format("2 January 2006"))
+	if err := u.mailer.Send(user.Email, "Your Talestoon account will be deleted", body); err != nil {
+		log.Printf("failed to send deletion notice to user %s: %v", user.ID, err)
+	}
+
+	return scheduledAt, nil
+}
+
+func (u *accountUsecase) CancelDeletion(userID uuid.UUID) error {
+	user, err := u.userRepo.FindByID(userID)
+	if err != nil {
+		return err
+	}
+
+	if user.DeletionScheduledAt == nil {
+		return errors.New("account is not scheduled for deletion")
+	}
+
+	return u.userRepo.SetDeletionScheduledAt(userID, nil)
+}
+
+// PurgeDueDeletions deletes accounts whose grace period has ended: their
+// comics are removed and the user record is anonymized in one transaction.
+// An account that fails is logged and skipped until the next run so it does
+// not hold up the others. It returns the number of accounts purged.
+func (u *accountUsecase) PurgeDueDeletions() (int, error) {
+	purged := 0
+	var failed []uuid.UUID
+	for {
+		users, err := u.userRepo.ListDueDeletions(time.Now(), failed, purgeBatchSize)
+		if err != nil {
+			return purged, err
+		}
+		if len(users) == 0 {
+			return purged, nil
+		}
+
+		for _, user := range users {
+			if err := u.userRepo.Anonymize(user.ID, time.Now()); err != nil {
+				log.Printf("failed to purge user %s: %v", user.ID, err)
+				failed = append(failed, user.ID)
+				continue
+			}
+			purged++
+		}
+	}
+}
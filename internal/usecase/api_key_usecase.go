@@ -0,0 +1,109 @@
+package usecase
+
+import (
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/pur108/talestoon-be/internal/domain"
+	"github.com/pur108/talestoon-be/pkg/utils"
+)
+
+const maxAPIKeysPerUser = 20
+
+type CreateAPIKeyInput struct {
+	Name      string     `json:"name"`
+	Scopes    []string   `json:"scopes"`
+	ExpiresAt *time.Time `json:"expires_at"`
+}
+
+type APIKeyUsecase interface {
+	Create(userID uuid.UUID, input CreateAPIKeyInput) (*domain.APIKey, string, error)
+	List(userID uuid.UUID) ([]domain.APIKey, error)
+	Revoke(id uuid.UUID, userID uuid.UUID) error
+}
+
+type apiKeyUsecase struct {
+	apiKeyRepo domain.APIKeyRepository
+}
+
+func NewAPIKeyUsecase(apiKeyRepo domain.APIKeyRepository) APIKeyUsecase {
+	return &apiKeyUsecase{apiKeyRepo}
+}
+
+// Create issues a new API key and returns it together with the raw key,
+// which is only available at creation time.
+func (u *apiKeyUsecase) Create(userID uuid.UUID, input CreateAPIKeyInput) (*domain.APIKey, string, error) {
+	name := strings.TrimSpace(input.Name)
+	if name == "" {
+		return nil, "", errors.New("name is required")
+	}
+
+	if len(input.Scopes) == 0 {
+		return nil, "", errors.New("at least one scope is required")
+	}
+	for _, scope := range input.Scopes {
+		if !validAPIKeyScope(scope) {
+			return nil, "", errors.New("invalid scope: " + scope)
+		}
+	}
+
+	if input.ExpiresAt != nil && input.ExpiresAt.Before(time.Now()) {
+		return nil, "", errors.New("expiry must be in the future")
+	}
+
+	existing, err := u.apiKeyRepo.ListByUserID(userID)
+	if err != nil {
+		return nil, "", err
+	}
+	active := 0
+	for _, key := range existing {
+		if key.RevokedAt == nil {
+			active++
+		}
+	}
+	if active >= maxAPIKeysPerUser {
+		return nil, "", errors.New("too many active API keys")
+	}
+
+	secret, err := utils.GenerateToken(32)
+	if err != nil {
+		return nil, "", err
+	}
+	rawKey := domain.APIKeyPrefix + secret
+
+	key := &domain.APIKey{
+		ID:        uuid.New(),
+		UserID:    userID,
+		Name:      name,
+		Prefix:    rawKey[:len(domain.APIKeyPrefix)+8],
+		KeyHash:   utils.HashToken(rawKey),
+		Scopes:    input.Scopes,
+		ExpiresAt: input.ExpiresAt,
+		CreatedAt: time.Now(),
+	}
+
+	if err := u.apiKeyRepo.Create(key); err != nil {
+		return nil, "", err
+	}
+
+	return key, rawKey, nil
+}
+
+func (u *apiKeyUsecase) List(userID uuid.UUID) ([]domain.APIKey, error) {
+	return u.apiKeyRepo.ListByUserID(userID)
+}
+
+func (u *apiKeyUsecase) Revoke(id uuid.UUID, userID uuid.UUID) error {
+	return u.apiKeyRepo.Revoke(id, userID)
+}
+
+func validAPIKeyScope(scope string) bool {
+	for _, s := range domain.APIKeyScopes {
+		if s == scope {
+			return true
+		}
+	}
+	return false
+}
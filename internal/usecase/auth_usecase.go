@@ -2,29 +2,122 @@ package usecase
 
 import (
 	"errors"
+	"fmt"
+	"log"
 	"os"
+	"strings"
 	"time"
 
 	"github.com/golang-jwt/jwt/v5"
 	"github.com/google/uuid"
 	"github.com/pur108/talestoon-be/internal/domain"
+	"github.com/pur108/talestoon-be/internal/keyring"
+	"github.com/pur108/talestoon-be/pkg/utils"
 	"golang.org/x/crypto/bcrypt"
 )
 
+const (
+	accessTokenTTL  = 15 * time.Minute
+	refreshTokenTTL = 30 * 24 * time.Hour
+	resetTokenTTL   = time.Hour
+	verifyTokenTTL  = 48 * time.Hour
+	challengeTTL    = 5 * time.Minute
+
+	// Failed logins inside loginAttemptWindow are throttled with exponential
+	// backoff once the free attempts are used up, and locked out for
+	// lockoutDuration once the lockout threshold is reached.
+	loginAttemptWindow         = time.Hour
+	lockoutDuration            = 15 * time.Minute
+	identifierFreeAttempts     = 3
+	identifierLockoutThreshold = 10
+	ipFreeAttempts             = 20
+	ipLockoutThreshold         = 100
+
+	// apiKeyTouchInterval and sessionTouchInterval limit how often last-use
+	// timestamps are written.
+	apiKeyTouchInterval  = time.Minute
+	sessionTouchInterval = time.Minute
+)
+
+// dummyPasswordHash is compared against when no account matches a login, so
+// that unknown identifiers take as long to reject as wrong passwords.
+const dummyPasswordHash = "$2a$10$Oe933Dz/dPiZ20riPlsUe.jEL6WvAdzvuMIseI.wQXYwDS69PTigm"
+
+const (
+	tokenTypeAccess    = "access"
+	tokenTypeChallenge = "2fa_challenge"
+)
+
+// AccessClaims are the claims carried by access tokens.
+type AccessClaims struct {
+	UserID    string          `json:"user_id"`
+	Role      domain.UserRole `json:"role"`
+	SessionID string          `json:"sid"`
+	Type      string          `json:"typ"`
+	jwt.RegisteredClaims
+}
+
+// challengeClaims are the claims of the short-lived token handed out between
+// the password and second-factor steps of a login.
+type challengeClaims struct {
+	UserID string `json:"user_id"`
+	Type   string `json:"typ"`
+	jwt.RegisteredClaims
+}
+
+// ClientInfo describes the client making an authentication request.
+type ClientInfo struct {
+	IP        string
+	UserAgent string
+}
+
+type TokenPair struct {
+	AccessToken      string    `json:"token"`
+	RefreshToken     string    `json:"refresh_token"`
+	ExpiresAt        time.Time `json:"expires_at"`
+	RefreshExpiresAt time.Time `json:"refresh_expires_at"`
+}
+
+// LoginResult carries either issued tokens or, for accounts with two-factor
+// authentication enabled, a challenge token to exchange via VerifyTwoFactor.
+type LoginResult struct {
+	Tokens            *TokenPair
+	User              *domain.User
+	TwoFactorRequired bool
+	ChallengeToken    string
+}
+
 type AuthUsecase interface {
-	SignUp(username, email, password string, role domain.UserRole) (*domain.User, error)
-	Login(identifier, password string) (string, *domain.User, error)
+	SignUp(username, email, password string) (*domain.User, error)
+	Login(identifier, password string, client ClientInfo) (*LoginResult, error)
+	CompleteLogin(user *domain.User, client ClientInfo) (*LoginResult, error)
+	VerifyTwoFactor(challengeToken, code string, client ClientInfo) (*TokenPair, *domain.User, error)
+	Refresh(refreshToken string) (*TokenPair, error)
+	Logout(userID, sessionID uuid.UUID, jti string) error
+	RevokeAllTokens(userID uuid.UUID) error
+	Authenticate(tokenString string) (*domain.Principal, error)
+	RequestPasswordReset(email string) error
+	ResetPassword(token, newPassword string) error
+	SendVerificationEmail(userID uuid.UUID) error
+	VerifyEmail(token string) error
+	ListLoginAttempts(identifier, ip string, failedOnly bool, limit int) ([]domain.LoginAttempt, error)
 }
 
 type authUsecase struct {
-	userRepo domain.UserRepository
+	userRepo         domain.UserRepository
+	tokenRepo        domain.TokenRepository
+	sessionRepo      domain.SessionRepository
+	loginAttemptRepo domain.LoginAttemptRepository
+	apiKeyRepo       domain.APIKeyRepository
+	mailer           domain.Mailer
+	keys             *keyring.KeyRing
 }
 
-func NewAuthUsecase(userRepo domain.UserRepository) AuthUsecase {
-	return &authUsecase{userRepo}
+func NewAuthUsecase(userRepo domain.UserRepository, tokenRepo domain.TokenRepository, sessionRepo domain.SessionRepository, loginAttemptRepo domain.LoginAttemptRepository, apiKeyRepo domain.APIKeyRepository, mailer domain.Mailer, keys *keyring.KeyRing) AuthUsecase {
+	return &authUsecase{userRepo, tokenRepo, sessionRepo, loginAttemptRepo, apiKeyRepo, mailer, keys}
 }
 
-func (u *authUsecase) SignUp(username, email, password string, role domain.UserRole) (*domain.User, error) {
+func (u *authUsecase) SignUp(username, email, password string) (*domain.User, error) {
 	existingUser, _ := u.userRepo.FindByEmailOrUsername(email)
 	if existingUser != nil {
 		return nil, errors.New("email or username already exists")
@@ -45,36 +138,630 @@ func (u *authUsecase) SignUp(username, email, password string, role domain.UserR
 		Username:     username,
 		Email:        email,
 		PasswordHash: string(hashedPassword),
-		Role:         role,
+		Role:         domain.RoleUser,
 	}
 
 	if err := u.userRepo.Create(user); err != nil {
 		return nil, err
 	}
 
+	if err := u.sendVerificationEmail(user); err != nil {
+		log.Printf("failed to send verification email to user %s: %v", user.ID, err)
+	}
+
 	return user, nil
 }
 
-func (u *authUsecase) Login(identifier, password string) (string, *domain.User, error) {
+func (u *authUsecase) Login(identifier, password string, client ClientInfo) (*LoginResult, error) {
+	attemptKey := strings.ToLower(strings.TrimSpace(identifier))
+	if err := u.checkThrottle(attemptKey, client); err != nil {
+		return nil, err
+	}
+
 	user, err := u.userRepo.FindByEmailOrUsername(identifier)
 	if err != nil {
-		return "", nil, errors.New("invalid credentials")
+		bcrypt.CompareHashAndPassword([]byte(dummyPasswordHash), []byte(password))
+		u.recordAttempt(attemptKey, client, nil, domain.LoginReasonInvalidCredentials)
+		return nil, domain.ErrInvalidCredentials
 	}
 
 	if err := bcrypt.CompareHashAndPassword([]byte(user.PasswordHash), []byte(password)); err != nil {
-		return "", nil, errors.New("invalid credentials")
+		u.recordAttempt(attemptKey, client, &user.ID, domain.LoginReasonInvalidCredentials)
+		return nil, domain.ErrInvalidCredentials
+	}
+
+	if user.PasswordResetRequired {
+		return nil, domain.ErrPasswordResetRequired
+	}
+
+	if err := u.checkAccountStatus(user); err != nil {
+		return nil, err
+	}
+
+	// Only a login that goes through counts as a success and resets the
+	// identifier's backoff.
+	u.recordAttempt(attemptKey, client, &user.ID, domain.LoginReasonSuccess)
+
+	return u.completeLogin(user, client)
+}
+
+// CompleteLogin finishes a login for a user whose first factor has already
+// been checked by an external identity provider.
+func (u *authUsecase) CompleteLogin(user *domain.User, client ClientInfo) (*LoginResult, error) {
+	if err := u.checkAccountStatus(user); err != nil {
+		return nil, err
+	}
+
+	return u.completeLogin(user, client)
+}
+
+// completeLogin hands out a second-factor challenge, or starts the session
+// right away for users without two-factor authentication.
+func (u *authUsecase) completeLogin(user *domain.User, client ClientInfo) (*LoginResult, error) {
+	if user.TwoFactorEnabledAt != nil {
+		challenge, err := u.signChallenge(user)
+		if err != nil {
+			return nil, err
+		}
+		return &LoginResult{TwoFactorRequired: true, ChallengeToken: challenge}, nil
+	}
+
+	pair, err := u.startSession(user, client)
+	if err != nil {
+		return nil, err
+	}
+
+	return &LoginResult{Tokens: pair, User: user}, nil
+}
+
+// VerifyTwoFactor completes a login started with Login by checking a TOTP or
+// recovery code against the user named in the challenge token.
+func (u *authUsecase) VerifyTwoFactor(challengeToken, code string, client ClientInfo) (*TokenPair, *domain.User, error) {
+	var claims challengeClaims
+	token, err := jwt.ParseWithClaims(challengeToken, &claims, u.keys.Keyfunc)
+	if err != nil || !token.Valid || claims.Type != tokenTypeChallenge || claims.ID == "" || claims.ExpiresAt == nil {
+		return nil, nil, domain.ErrInvalidToken
+	}
+	if revoked, err := u.tokenRepo.IsAccessTokenRevoked(claims.ID); err != nil || revoked {
+		return nil, nil, domain.ErrInvalidToken
+	}
+
+	userID, err := uuid.Parse(claims.UserID)
+	if err != nil {
+		return nil, nil, domain.ErrInvalidToken
+	}
+
+	user, err := u.userRepo.FindByID(userID)
+	if err != nil || user.TwoFactorEnabledAt == nil {
+		return nil, nil, domain.ErrInvalidToken
+	}
+
+	attemptKey := "2fa:" + user.ID.String()
+	if err := u.checkThrottle(attemptKey, client); err != nil {
+		return nil, nil, err
+	}
+
+	if err := verifySecondFactor(u.userRepo, u.tokenRepo, user, code); err != nil {
+		u.recordAttempt(attemptKey, client, &user.ID, domain.LoginReasonInvalidTwoFactor)
+		return nil, nil, err
+	}
+
+	// The challenge is single-use: revoking its ID fails if another request
+	// already completed this login.
+	if err := u.tokenRepo.RevokeAccessToken(&domain.RevokedToken{
+		JTI:       claims.ID,
+		UserID:    user.ID,
+		ExpiresAt: claims.ExpiresAt.Time,
+		CreatedAt: time.Now(),
+	}); err != nil {
+		return nil, nil, domain.ErrInvalidToken
+	}
+
+	u.recordAttempt(attemptKey, client, &user.ID, domain.LoginReasonSuccess)
+
+	if err := u.checkAccountStatus(user); err != nil {
+		return nil, nil, err
+	}
+
+	pair, err := u.startSession(user, client)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return pair, user, nil
+}
+
+// Refresh exchanges a refresh token for a new token pair. Refresh tokens are
+// single use: presenting one that was already rotated is treated as theft and
+// revokes every token in its session.
+func (u *authUsecase) Refresh(refreshToken string) (*TokenPair, error) {
+	stored, err := u.tokenRepo.FindRefreshTokenByHash(utils.HashToken(refreshToken))
+	if err != nil {
+		return nil, domain.ErrInvalidToken
+	}
+
+	if stored.RevokedAt != nil {
+		_ = u.tokenRepo.RevokeSessionRefreshTokens(stored.SessionID)
+		return nil, domain.ErrInvalidToken
+	}
+
+	if time.Now().After(stored.ExpiresAt) {
+		return nil, domain.ErrInvalidToken
+	}
+
+	if _, err := u.activeSession(stored.SessionID); err != nil {
+		return nil, err
+	}
+
+	user, err := u.userRepo.FindByID(stored.UserID)
+	if err != nil {
+		return nil, domain.ErrInvalidToken
+	}
+
+	if err := u.checkAccountStatus(user); err != nil {
+		return nil, err
+	}
+
+	pair, next, err := u.issueTokens(user, stored.SessionID)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := u.tokenRepo.RotateRefreshToken(stored.ID, next); err != nil {
+		if errors.Is(err, domain.ErrInvalidToken) {
+			_ = u.tokenRepo.RevokeSessionRefreshTokens(stored.SessionID)
+		}
+		return nil, err
+	}
+
+	return pair, nil
+}
+
+func (u *authUsecase) Logout(userID, sessionID uuid.UUID, jti string) error {
+	if err := u.sessionRepo.Revoke(sessionID, userID); err != nil && !errors.Is(err, domain.ErrNotFound) {
+		return err
+	}
+
+	if err := u.tokenRepo.RevokeSessionRefreshTokens(sessionID); err != nil {
+		return err
+	}
+
+	return u.tokenRepo.RevokeAccessToken(&domain.RevokedToken{
+		JTI:       jti,
+		UserID:    userID,
+		ExpiresAt: time.Now().Add(accessTokenTTL),
+	})
+}
+
+// RevokeAllTokens cuts a user off everywhere: outstanding access tokens stop
+// validating and no refresh token can be exchanged any more.
+func (u *authUsecase) RevokeAllTokens(userID uuid.UUID) error {
+	if err := u.userRepo.SetTokensRevokedAt(userID, time.Now()); err != nil {
+		return err
+	}
+	if err := u.sessionRepo.RevokeAll(userID); err != nil {
+		return err
+	}
+	return u.tokenRepo.RevokeUserRefreshTokens(userID)
+}
+
+// Authenticate validates an access token or API key and resolves the
+// principal behind it. Role and verification state come from the database so
+// changes take effect without waiting for the token to be refreshed.
+func (u *authUsecase) Authenticate(tokenString string) (*domain.Principal, error) {
+	if strings.HasPrefix(tokenString, domain.APIKeyPrefix) {
+		return u.authenticateAPIKey(tokenString)
+	}
+
+	var claims AccessClaims
+	token, err := jwt.ParseWithClaims(tokenString, &claims, u.keys.Keyfunc, jwt.WithIssuedAt())
+	if err != nil || !token.Valid || claims.Type != tokenTypeAccess || claims.IssuedAt == nil {
+		return nil, domain.ErrInvalidToken
+	}
+
+	revoked, err := u.tokenRepo.IsAccessTokenRevoked(claims.ID)
+	if err != nil {
+		return nil, err
+	}
+	if revoked {
+		return nil, domain.ErrInvalidToken
+	}
+
+	userID, err := uuid.Parse(claims.UserID)
+	if err != nil {
+		return nil, domain.ErrInvalidToken
+	}
+
+	sessionID, err := uuid.Parse(claims.SessionID)
+	if err != nil {
+		return nil, domain.ErrInvalidToken
+	}
+
+	user, err := u.userRepo.FindByID(userID)
+	if err != nil {
+		return nil, domain.ErrInvalidToken
+	}
+
+	if user.TokensRevokedAt != nil && claims.IssuedAt.Unix() < user.TokensRevokedAt.Unix() {
+		return nil, domain.ErrInvalidToken
+	}
+
+	if err := u.checkAccountStatus(user); err != nil {
+		return nil, err
+	}
+
+	if _, err := u.activeSession(sessionID); err != nil {
+		return nil, err
+	}
+
+	return &domain.Principal{
+		UserID:        user.ID,
+		Role:          user.Role,
+		SessionID:     sessionID,
+		TokenID:       claims.ID,
+		Scopes:        []string{domain.ScopeAll},
+		EmailVerified: user.EmailVerifiedAt != nil,
+	}, nil
+}
+
+func (u *authUsecase) authenticateAPIKey(rawKey string) (*domain.Principal, error) {
+	key, err := u.apiKeyRepo.FindByHash(utils.HashToken(rawKey))
+	if err != nil {
+		return nil, domain.ErrInvalidToken
+	}
+
+	now := time.Now()
+	if key.RevokedAt != nil || (key.ExpiresAt != nil && now.After(*key.ExpiresAt)) {
+		return nil, domain.ErrInvalidToken
+	}
+
+	user, err := u.userRepo.FindByID(key.UserID)
+	if err != nil {
+		return nil, domain.ErrInvalidToken
+	}
+
+	if err := u.checkAccountStatus(user); err != nil {
+		return nil, err
+	}
+
+	if key.LastUsedAt == nil || now.Sub(*key.LastUsedAt) > apiKeyTouchInterval {
+		if err := u.apiKeyRepo.TouchLastUsed(key.ID, now); err != nil {
+			log.Printf("failed to update last use of API key %s: %v", key.ID, err)
+		}
+	}
+
+	return &domain.Principal{
+		UserID:        user.ID,
+		Role:          user.Role,
+		TokenID:       key.ID.String(),
+		Scopes:        key.Scopes,
+		EmailVerified: user.EmailVerifiedAt != nil,
+		APIKeyID:      &key.ID,
+	}, nil
+}
+
+// RequestPasswordReset mails a reset link to the account registered with
+// email. Unknown addresses are ignored so callers cannot probe for accounts.
+func (u *authUsecase) RequestPasswordReset(email string) error {
+	user, err := u.userRepo.FindByEmail(email)
+	if err != nil {
+		return nil
+	}
+
+	rawToken, err := utils.GenerateToken(32)
+	if err != nil {
+		return err
+	}
+
+	resetToken := &domain.PasswordResetToken{
+		ID:        uuid.New(),
+		UserID:    user.ID,
+		TokenHash: utils.HashToken(rawToken),
+		ExpiresAt: time.Now().Add(resetTokenTTL),
+		CreatedAt: time.Now(),
+	}
+	if err := u.tokenRepo.CreatePasswordResetToken(resetToken); err != nil {
+		return err
+	}
+
+	link := fmt.Sprintf("%s/reset-password?token=%s", os.Getenv("APP_URL"), rawToken)
+	body := fmt.Sprintf("Hi %s,\n\nUse the link below to choose a new password. It expires in one hour.\n\n%s\n\nIf you did not ask for this, you can ignore this email.", user.Username, link)
+
+	return u.mailer.Send(user.Email, "Reset your Talestoon password", body)
+}
+
+// ResetPassword sets a new password using a token from RequestPasswordReset.
+// The token is consumed, any other outstanding reset tokens are invalidated
+// and every existing session is signed out.
+func (u *authUsecase) ResetPassword(token, newPassword string) error {
+	resetToken, err := u.tokenRepo.FindPasswordResetTokenByHash(utils.HashToken(token))
+	if err != nil {
+		return domain.ErrInvalidToken
+	}
+
+	if resetToken.UsedAt != nil || time.Now().After(resetToken.ExpiresAt) {
+		return domain.ErrInvalidToken
+	}
+
+	if err := u.tokenRepo.ConsumePasswordResetToken(resetToken.ID); err != nil {
+		return err
+	}
+
+	hashedPassword, err := bcrypt.GenerateFromPassword([]byte(newPassword), bcrypt.DefaultCost)
+	if err != nil {
+		return err
+	}
+
+	if err := u.userRepo.UpdatePassword(resetToken.UserID, string(hashedPassword)); err != nil {
+		return err
+	}
+
+	if err := u.tokenRepo.InvalidateUserPasswordResetTokens(resetToken.UserID); err != nil {
+		return err
+	}
+
+	return u.RevokeAllTokens(resetToken.UserID)
+}
+
+// SendVerificationEmail mails a fresh verification link to a user whose
+// address has not been verified yet.
+func (u *authUsecase) SendVerificationEmail(userID uuid.UUID) error {
+	user, err := u.userRepo.FindByID(userID)
+	if err != nil {
+		return err
+	}
+
+	if user.EmailVerifiedAt != nil {
+		return errors.New("email is already verified")
+	}
+
+	return u.sendVerificationEmail(user)
+}
+
+func (u *authUsecase) VerifyEmail(token string) error {
+	verificationToken, err := u.tokenRepo.FindEmailVerificationTokenByHash(utils.HashToken(token))
+	if err != nil {
+		return domain.ErrInvalidToken
+	}
+
+	if verificationToken.UsedAt != nil || time.Now().After(verificationToken.ExpiresAt) {
+		return domain.ErrInvalidToken
+	}
+
+	if err := u.tokenRepo.ConsumeEmailVerificationToken(verificationToken.ID); err != nil {
+		return err
+	}
+
+	return u.userRepo.MarkEmailVerified(verificationToken.UserID, time.Now())
+}
+
+func (u *authUsecase) sendVerificationEmail(user *domain.User) error {
+	rawToken, err := utils.GenerateToken(32)
+	if err != nil {
+		return err
+	}
+
+	verificationToken := &domain.EmailVerificationToken{
+		ID:        uuid.New(),
+		UserID:    user.ID,
+		TokenHash: utils.HashToken(rawToken),
+		ExpiresAt: time.Now().Add(verifyTokenTTL),
+		CreatedAt: time.Now(),
+	}
+	if err := u.tokenRepo.CreateEmailVerificationToken(verificationToken); err != nil {
+		return err
+	}
+
+	link := fmt.Sprintf("%s/verify-email?token=%s", os.Getenv("APP_URL"), rawToken)
+	body := fmt.Sprintf("Hi %s,\n\nPlease confirm your email address by opening the link below. It expires in 48 hours.\n\n%s", user.Username, link)
+
+	return u.mailer.Send(user.Email, "Verify your Talestoon email", body)
+}
+
+func (u *authUsecase) ListLoginAttempts(identifier, ip string, failedOnly bool, limit int) ([]domain.LoginAttempt, error) {
+	return u.loginAttemptRepo.List(strings.ToLower(identifier), ip, failedOnly, limit)
+}
+
+// checkAccountStatus returns a *domain.SuspendedError for suspended and
+// banned accounts. A restriction that has run out is lifted on the spot
+// rather than waiting for the background job.
+func (u *authUsecase) checkAccountStatus(user *domain.User) error {
+	if user.IsRestricted(time.Now()) {
+		return &domain.SuspendedError{
+			Status: user.Status,
+			Reason: user.SuspensionReason,
+			Until:  user.SuspendedUntil,
+		}
+	}
+
+	if user.Status != "" && user.Status != domain.UserActive {
+		if err := u.userRepo.SetStatus(user.ID, domain.UserActive, "", nil); err != nil {
+			log.Printf("failed to lift expired restriction of user %s: %v", user.ID, err)
+		}
+		user.Status = domain.UserActive
+		user.SuspensionReason = ""
+		user.SuspendedUntil = nil
+	}
+
+	return nil
+}
+
+// checkThrottle returns a *domain.ThrottledError when the identifier or the
+// client IP has failed too often recently. A successful login resets the
+// identifier's count but not the IP's.
+func (u *authUsecase) checkThrottle(attemptKey string, client ClientInfo) error {
+	now := time.Now()
+	since := now.Add(-loginAttemptWindow)
+
+	identifierSince := since
+	lastSuccess, err := u.loginAttemptRepo.LastSuccessAt(attemptKey)
+	if err != nil {
+		return err
+	}
+	if lastSuccess != nil && lastSuccess.After(identifierSince) {
+		identifierSince = *lastSuccess
+	}
+
+	failures, lastFailure, err := u.loginAttemptRepo.CountIdentifierFailures(attemptKey, identifierSince)
+	if err != nil {
+		return err
+	}
+	wait := retryAfter(failures, lastFailure, identifierFreeAttempts, identifierLockoutThreshold, now)
+
+	if client.IP != "" {
+		ipFailures, ipLastFailure, err := u.loginAttemptRepo.CountIPFailures(client.IP, since)
+		if err != nil {
+			return err
+		}
+		if ipWait := retryAfter(ipFailures, ipLastFailure, ipFreeAttempts, ipLockoutThreshold, now); ipWait > wait {
+			wait = ipWait
+		}
+	}
+
+	if wait > 0 {
+		u.recordAttempt(attemptKey, client, nil, domain.LoginReasonThrottled)
+		return &domain.ThrottledError{RetryAfter: wait}
+	}
+	return nil
+}
+
+func (u *authUsecase) recordAttempt(attemptKey string, client ClientInfo, userID *uuid.UUID, reason string) {
+	err := u.loginAttemptRepo.Create(&domain.LoginAttempt{
+		ID:         uuid.New(),
+		Identifier: attemptKey,
+		IP:         client.IP,
+		UserAgent:  client.UserAgent,
+		UserID:     userID,
+		Success:    reason == domain.LoginReasonSuccess,
+		Reason:     reason,
+		CreatedAt:  time.Now(),
+	})
+	if err != nil {
+		log.Printf("failed to record login attempt for %q: %v", attemptKey, err)
+	}
+}
+
+// retryAfter returns how long a caller with the given number of recent
+// failures must still wait. The delay doubles with every failure past the
+// free attempts and is capped at lockoutDuration.
+func retryAfter(failures int64, lastFailure *time.Time, free, lockoutAt int64, now time.Time) time.Duration {
+	if failures < free || lastFailure == nil {
+		return 0
+	}
+
+	delay := lockoutDuration
+	if failures < lockoutAt {
+		delay = time.Second << (failures - free)
+		if delay > lockoutDuration {
+			delay = lockoutDuration
+		}
+	}
+
+	return lastFailure.Add(delay).Sub(now)
+}
+
+// startSession records a new session for the client, issues its first token
+// pair and persists the refresh token.
+func (u *authUsecase) startSession(user *domain.User, client ClientInfo) (*TokenPair, error) {
+	now := time.Now()
+	session := &domain.Session{
+		ID:         uuid.New(),
+		UserID:     user.ID,
+		Device:     describeDevice(client.UserAgent),
+		UserAgent:  client.UserAgent,
+		IP:         client.IP,
+		LastSeenAt: now,
+		CreatedAt:  now,
+	}
+	if err := u.sessionRepo.Create(session); err != nil {
+		return nil, err
+	}
+
+	pair, refreshToken, err := u.issueTokens(user, session.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := u.tokenRepo.CreateRefreshToken(refreshToken); err != nil {
+		return nil, err
 	}
 
-	token := jwt.NewWithClaims(jwt.SigningMethodHS256, jwt.MapClaims{
-		"user_id": user.ID.String(),
-		"role":    user.Role,
-		"exp":     time.Now().Add(time.Hour * 72).Unix(),
+	return pair, nil
+}
+
+// activeSession loads a session that has not been revoked and records that it
+// was seen, writing at most once per sessionTouchInterval.
+func (u *authUsecase) activeSession(id uuid.UUID) (*domain.Session, error) {
+	session, err := u.sessionRepo.FindByID(id)
+	if err != nil || session.RevokedAt != nil {
+		return nil, domain.ErrInvalidToken
+	}
+
+	if now := time.Now(); now.Sub(session.LastSeenAt) > sessionTouchInterval {
+		if err := u.sessionRepo.Touch(session.ID, now); err != nil {
+			log.Printf("failed to update last use of session %s: %v", session.ID, err)
+		}
+	}
+
+	return session, nil
+}
+
+// signChallenge returns a short-lived token proving the password step of a
+// two-factor login succeeded. It is not accepted as an access token.
+func (u *authUsecase) signChallenge(user *domain.User) (string, error) {
+	now := time.Now()
+	return u.keys.Sign(challengeClaims{
+		UserID: user.ID.String(),
+		Type:   tokenTypeChallenge,
+		RegisteredClaims: jwt.RegisteredClaims{
+			ID:        uuid.NewString(),
+			IssuedAt:  jwt.NewNumericDate(now),
+			ExpiresAt: jwt.NewNumericDate(now.Add(challengeTTL)),
+		},
 	})
+}
+
+// issueTokens signs a new access token and builds the matching refresh token
+// for the given session. The refresh token is returned unsaved so callers can
+// decide whether to create it or rotate an existing one.
+func (u *authUsecase) issueTokens(user *domain.User, sessionID uuid.UUID) (*TokenPair, *domain.RefreshToken, error) {
+	now := time.Now()
+	expiresAt := now.Add(accessTokenTTL)
+
+	accessToken, err := u.keys.Sign(AccessClaims{
+		UserID:    user.ID.String(),
+		Role:      user.Role,
+		SessionID: sessionID.String(),
+		Type:      tokenTypeAccess,
+		RegisteredClaims: jwt.RegisteredClaims{
+			ID:        uuid.NewString(),
+			Subject:   user.ID.String(),
+			IssuedAt:  jwt.NewNumericDate(now),
+			ExpiresAt: jwt.NewNumericDate(expiresAt),
+		},
+	})
+	if err != nil {
+		return nil, nil, err
+	}
 
-	t, err := token.SignedString([]byte(os.Getenv("JWT_SECRET")))
+	rawRefreshToken, err := utils.GenerateToken(32)
 	if err != nil {
-		return "", nil, err
+		return nil, nil, err
+	}
+
+	refreshToken := &domain.RefreshToken{
+		ID:        uuid.New(),
+		UserID:    user.ID,
+		SessionID: sessionID,
+		TokenHash: utils.HashToken(rawRefreshToken),
+		ExpiresAt: now.Add(refreshTokenTTL),
+		CreatedAt: now,
 	}
 
-	return t, user, nil
+	return &TokenPair{
+		AccessToken:      accessToken,
+		RefreshToken:     rawRefreshToken,
+		ExpiresAt:        expiresAt,
+		RefreshExpiresAt: refreshToken.ExpiresAt,
+	}, refreshToken, nil
 }
@@ -1,7 +1,10 @@
 package usecase
 
 import (
+	"errors"
 	"fmt"
+	"log"
+	"strings"
 	"time"
 
 	"github.com/google/uuid"
@@ -11,24 +14,41 @@ import (
 
 type ComicUsecase interface {
 	CreateComic(input CreateComicInput) (*domain.Comic, error)
-	GetComic(id uuid.UUID) (*domain.Comic, error)
-	GetChapter(id uuid.UUID) (*domain.Chapter, error)
+	GetComic(id uuid.UUID, viewer *domain.Principal) (*domain.Comic, error)
+	GetChapter(id uuid.UUID, viewer *domain.Principal) (*domain.Chapter, error)
 	CreateChapter(comicID uuid.UUID, creatorID uuid.UUID, input CreateChapterInput) (*domain.Chapter, error)
-	ListComics() ([]domain.Comic, error)
+	ListComics(viewer *domain.Principal, input ListComicsInput) (*domain.ComicPage, error)
 	ListMyComics(creatorID uuid.UUID) ([]domain.Comic, error)
+	GetCreatorPage(username string, viewer *domain.Principal) (*CreatorPage, error)
 	UpdateComic(id uuid.UUID, creatorID uuid.UUID, input UpdateComicInput) (*domain.Comic, error)
 	DeleteComic(id uuid.UUID, creatorID uuid.UUID) error
 }
 
 type comicUsecase struct {
-	comicRepo domain.ComicRepository
-	userRepo  domain.UserRepository
+	comicRepo           domain.ComicRepository
+	tagRepo             domain.TagRepository
+	userRepo            domain.UserRepository
+	preferencesRepo     domain.PreferencesRepository
+	followRepo          domain.FollowRepository
+	notificationUsecase NotificationUsecase
 }
 
-func NewComicUsecase(comicRepo domain.ComicRepository, userRepo domain.UserRepository) ComicUsecase {
-	return &comicUsecase{comicRepo, userRepo}
+func NewComicUsecase(comicRepo domain.ComicRepository, tagRepo domain.TagRepository, userRepo domain.UserRepository, preferencesRepo domain.PreferencesRepository, followRepo domain.FollowRepository, notificationUsecase NotificationUsecase) ComicUsecase {
+	return &comicUsecase{comicRepo, tagRepo, userRepo, preferencesRepo, followRepo, notificationUsecase}
 }
 
+var (
+	// ErrNSFWHidden is returned for NSFW comics when an adult reader has not
+	// opted in to NSFW content.
+	ErrNSFWHidden = errors.New("this comic is marked NSFW; enable NSFW content in your preferences to view it")
+	// ErrAgeRestricted is returned for NSFW chapters when the reader is
+	// anonymous or has not confirmed they are an adult.
+	ErrAgeRestricted = errors.New("this content is restricted to readers aged 18 or older")
+	// ErrInvalidListQuery wraps catalog query parameters that are not
+	// supported.
+	ErrInvalidListQuery = errors.New("invalid comic query")
+)
+
 type CreateComicInput struct {
 	CreatorID   uuid.UUID                 `json:"creator_id"`
 	Title       domain.MultilingualText   `json:"title"`
@@ -55,6 +75,9 @@ type UpdateComicInput struct {
 	Description domain.MultilingualText `json:"description"`
 	Author      string                  `json:"author"`
 	Genres      []string                `json:"genres"`
+	// Tags replaces the comic's tags when present; omit it to leave them
+	// unchanged.
+	Tags *[]domain.MultilingualText `json:"tags"`
 	//ThumbnailURL        string                  `json:"thumbnail_url"`
 	CoverImageURL       string             `json:"cover_image_url"`
 	BannerImageURL      string             `json:"banner_image_url"`
@@ -66,6 +89,33 @@ type UpdateComicInput struct {
 	DefaultUnlockType   string             `json:"default_unlock_type"`
 }
 
+// CreatorPage is the public page of a creator: their profile and published
+// catalog.
+type CreatorPage struct {
+	Profile    *domain.PublicProfile `json:"profile"`
+	Comics     []domain.Comic        `json:"comics"`
+	ComicCount int                   `json:"comic_count"`
+}
+
+const (
+	defaultComicPageSize = 20
+	maxComicPageSize     = 50
+)
+
+// ListComicsInput holds the catalog query parameters. Creator is a username;
+// Cursor is the next_cursor of the previous page.
+type ListComicsInput struct {
+	Genre   string
+	Tag     string
+	Status  domain.ComicStatus
+	NSFW    *bool
+	Author  string
+	Creator string
+	Sort    domain.ComicSort
+	Cursor  string
+	Limit   int
+}
+
 type CreateChapterInput struct {
 	Title         string   `json:"title"`
 	ChapterNumber int      `json:"chapter_number"`
@@ -96,31 +146,9 @@ func (u *comicUsecase) CreateComic(input CreateComicInput) (*domain.Comic, error
 		UpdatedAt: time.Now(),
 	}
 
-	var tags []domain.Tag
-	for _, t := range input.Tags {
-		tagID := uuid.New()
-		slug := utils.SimpleSlug(t.En)
-
-		tags = append(tags, domain.Tag{
-			ID:        tagID,
-			Slug:      slug,
-			CreatedAt: time.Now(),
-			UpdatedAt: time.Now(),
-			Translations: []domain.TagTranslation{
-				{
-					ID:       uuid.New(),
-					TagID:    tagID,
-					Language: "en",
-					Name:     t.En,
-				},
-				{
-					ID:       uuid.New(),
-					TagID:    tagID,
-					Language: "th",
-					Name:     t.Th,
-				},
-			},
-		})
+	tags, err := u.resolveTags(input.Tags)
+	if err != nil {
+		return nil, err
 	}
 	comic.Tags = tags
 
@@ -128,12 +156,6 @@ func (u *comicUsecase) CreateComic(input CreateComicInput) (*domain.Comic, error
 		return nil, err
 	}
 
-	user, err := u.userRepo.FindByID(input.CreatorID)
-	if err == nil && user.Role == domain.RoleUser {
-		user.Role = domain.RoleCreator
-		_ = u.userRepo.Update(user)
-	}
-
 	return comic, nil
 }
 
@@ -183,24 +205,222 @@ func (u *comicUsecase) CreateChapter(comicID uuid.UUID, creatorID uuid.UUID, inp
 		return nil, err
 	}
 
+	u.notifySubscribers(comic, chapter)
+
 	return chapter, nil
 }
 
+// notifySubscribers tells the comic's subscribers about a newly published
+// chapter. Nothing is sent while the comic is a draft or private.
+func (u *comicUsecase) notifySubscribers(comic *domain.Comic, chapter *domain.Chapter) {
+	if comic.Status == domain.ComicDraft || comic.Visibility == domain.VisibilityPrivate {
+		return
+	}
+
+	subscriberIDs, err := u.followRepo.ListSubscriberIDs(comic.ID)
+	if err != nil {
+		log.Printf("failed to list subscribers of comic %s: %v", comic.ID, err)
+		return
+	}
+
+	title := fmt.Sprintf("New chapter of %s", comic.Title.In(domain.LocaleEn))
+	body := fmt.Sprintf("Chapter %d: %s", chapter.ChapterNumber, chapter.Title)
+	u.notificationUsecase.Notify(domain.NotificationNewChapter, subscriberIDs, title, body, map[string]interface{}{
+		"comic_id":       comic.ID,
+		"chapter_id":     chapter.ID,
+		"chapter_number": chapter.ChapterNumber,
+	})
+}
+
 func nowPtr() *time.Time {
 	t := time.Now()
 	return &t
 }
 
-func (u *comicUsecase) GetComic(id uuid.UUID) (*domain.Comic, error) {
-	return u.comicRepo.GetComicByID(id)
+// GetComic returns a comic if viewer may see it. Drafts and private comics
+// are only visible to their creator and admins. NSFW comics are redacted for
+// readers who are not confirmed adults and refused to adults who have not
+// opted in; viewer is nil for anonymous requests.
+func (u *comicUsecase) GetComic(id uuid.UUID, viewer *domain.Principal) (*domain.Comic, error) {
+	comic, err := u.comicRepo.GetComicByID(id)
+	if err != nil {
+		return nil, err
+	}
+
+	if !canViewComic(comic, viewer) {
+		return nil, domain.ErrNotFound
+	}
+
+	if !isOwnerOrAdmin(comic, viewer) {
+		creator, err := u.userRepo.FindByID(comic.CreatorID)
+		if err == nil && isBanned(creator) {
+			return nil, domain.ErrNotFound
+		}
+	}
+
+	reader, err := u.reader(viewer)
+	if err != nil {
+		return nil, err
+	}
+
+	if comic.NSFW && !isOwnerOrAdmin(comic, viewer) {
+		if !reader.adult {
+			comic.Redact()
+		} else if !reader.preferences.ShowNSFW {
+			return nil, ErrNSFWHidden
+		}
+	}
+
+	comic.Localize(reader.preferences.Locale)
+	return comic, nil
+}
+
+func canViewComic(comic *domain.Comic, viewer *domain.Principal) bool {
+	if comic.Visibility != domain.VisibilityPrivate && comic.Status != domain.ComicDraft {
+		return true
+	}
+	return isOwnerOrAdmin(comic, viewer)
+}
+
+func isOwnerOrAdmin(comic *domain.Comic, viewer *domain.Principal) bool {
+	return viewer != nil && (viewer.UserID == comic.CreatorID || viewer.Role == domain.RoleAdmin)
+}
+
+// isBanned reports whether user is under a ban that is still in effect.
+func isBanned(user *domain.User) bool {
+	return user.Status == domain.UserBanned && user.IsRestricted(time.Now())
+}
+
+// GetChapter returns a chapter if viewer may see the comic it belongs to.
+// Chapters of NSFW comics are refused outright to readers who are not
+// confirmed adults, since their content is the images themselves.
+func (u *comicUsecase) GetChapter(id uuid.UUID, viewer *domain.Principal) (*domain.Chapter, error) {
+	chapter, err := u.comicRepo.GetChapterByID(id)
+	if err != nil {
+		return nil, err
+	}
+
+	season, err := u.comicRepo.GetSeasonByID(chapter.SeasonID)
+	if err != nil {
+		return nil, err
+	}
+
+	comic, err := u.GetComic(season.ComicID, viewer)
+	if err != nil {
+		return nil, err
+	}
+
+	if comic.AgeRestricted {
+		return nil, ErrAgeRestricted
+	}
+
+	return chapter, nil
+}
+
+// ListComics returns a page of the public catalog with the request's filters
+// and the viewer's content filters applied.
+func (u *comicUsecase) ListComics(viewer *domain.Principal, input ListComicsInput) (*domain.ComicPage, error) {
+	reader, err := u.reader(viewer)
+	if err != nil {
+		return nil, err
+	}
+
+	filter := reader.catalogFilter()
+	filter.Genre = strings.TrimSpace(input.Genre)
+	filter.TagSlug = strings.ToLower(strings.TrimSpace(input.Tag))
+	filter.NSFW = input.NSFW
+	filter.Author = strings.TrimSpace(input.Author)
+	filter.Sort = domain.SortUpdated
+	filter.Cursor = input.Cursor
+	filter.Limit = input.Limit
+
+	if input.NSFW != nil && *input.NSFW && !reader.showNSFW() {
+		return nil, ErrNSFWHidden
+	}
+
+	switch input.Status {
+	case "":
+	case domain.ComicPublished, domain.ComicHiatus, domain.ComicCompleted:
+		filter.Status = input.Status
+	default:
+		return nil, fmt.Errorf("%w: status must be published, hiatus or completed", ErrInvalidListQuery)
+	}
+
+	switch input.Sort {
+	case "":
+	case domain.SortUpdated, domain.SortCreated, domain.SortTitle, domain.SortPopularity:
+		filter.Sort = input.Sort
+	default:
+		return nil, fmt.Errorf("%w: sort must be updated, created, title or popularity", ErrInvalidListQuery)
+	}
+
+	if filter.Limit <= 0 || filter.Limit > maxComicPageSize {
+		filter.Limit = defaultComicPageSize
+	}
+
+	if input.Creator != "" {
+		creator, err := u.userRepo.FindByUsername(input.Creator)
+		if err != nil {
+			return &domain.ComicPage{Comics: []domain.Comic{}}, nil
+		}
+		filter.CreatorID = &creator.ID
+	}
+
+	page, err := u.comicRepo.ListComics(filter)
+	if err != nil {
+		return nil, err
+	}
+
+	for i := range page.Comics {
+		page.Comics[i].Localize(reader.preferences.Locale)
+	}
+	return page, nil
+}
+
+// reader is what the public comic endpoints need to know about a viewer.
+type reader struct {
+	preferences *domain.UserPreferences
+	adult       bool
 }
 
-func (u *comicUsecase) GetChapter(id uuid.UUID) (*domain.Chapter, error) {
-	return u.comicRepo.GetChapterByID(id)
+// showNSFW reports whether NSFW comics should be shown in full. Preferences
+// saved before the age gate existed may opt in without a birth date.
+func (r *reader) showNSFW() bool {
+	return r.adult && r.preferences.ShowNSFW
 }
 
-func (u *comicUsecase) ListComics() ([]domain.Comic, error) {
-	return u.comicRepo.ListComics()
+// catalogFilter is the part of a ComicFilter that the reader's preferences
+// decide.
+func (r *reader) catalogFilter() domain.ComicFilter {
+	return domain.ComicFilter{
+		ExcludeNSFW:  !r.showNSFW(),
+		HiddenGenres: r.preferences.HiddenGenres,
+		HiddenTags:   r.preferences.HiddenTags,
+	}
+}
+
+func (u *comicUsecase) reader(viewer *domain.Principal) (*reader, error) {
+	return loadReader(u.userRepo, u.preferencesRepo, viewer)
+}
+
+// loadReader loads the viewer's preferences and age, with the defaults for
+// anonymous readers.
+func loadReader(userRepo domain.UserRepository, preferencesRepo domain.PreferencesRepository, viewer *domain.Principal) (*reader, error) {
+	if viewer == nil {
+		return &reader{preferences: domain.DefaultPreferences(uuid.Nil)}, nil
+	}
+
+	preferences, err := preferencesRepo.FindByUserID(viewer.UserID)
+	if err != nil {
+		return nil, err
+	}
+
+	user, err := userRepo.FindByID(viewer.UserID)
+	if err != nil {
+		return nil, err
+	}
+
+	return &reader{preferences: preferences, adult: user.IsAdult(time.Now())}, nil
 }
 
 func (u *comicUsecase) ListMyComics(creatorID uuid.UUID) ([]domain.Comic, error) {
@@ -208,11 +428,42 @@ func (u *comicUsecase) ListMyComics(creatorID uuid.UUID) ([]domain.Comic, error)
 	if err != nil {
 		return nil, err
 	}
-	fmt.Printf("ListMyComics Usecase: User found: %s. Querying by author: %s\n", user.Username, user.Username)
 
 	return u.comicRepo.ListComicsByCreatorID(user.ID)
 }
 
+// GetCreatorPage returns a creator's profile and public catalog. NSFW comics
+// stay listed but are redacted unless the viewer has opted in.
+func (u *comicUsecase) GetCreatorPage(username string, viewer *domain.Principal) (*CreatorPage, error) {
+	user, err := u.userRepo.FindByUsername(username)
+	if err != nil || (user.Role != domain.RoleCreator && user.Role != domain.RoleAdmin) || isBanned(user) {
+		return nil, domain.ErrNotFound
+	}
+
+	comics, err := u.comicRepo.ListPublicComicsByCreatorID(user.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	reader, err := u.reader(viewer)
+	if err != nil {
+		return nil, err
+	}
+
+	for i := range comics {
+		if comics[i].NSFW && !reader.showNSFW() && !isOwnerOrAdmin(&comics[i], viewer) {
+			comics[i].Redact()
+		}
+		comics[i].Localize(reader.preferences.Locale)
+	}
+
+	return &CreatorPage{
+		Profile:    user.PublicProfile(),
+		Comics:     comics,
+		ComicCount: len(comics),
+	}, nil
+}
+
 func (u *comicUsecase) UpdateComic(id uuid.UUID, creatorID uuid.UUID, input UpdateComicInput) (*domain.Comic, error) {
 	comic, err := u.comicRepo.GetComicByID(id)
 	if err != nil {
@@ -239,13 +490,64 @@ func (u *comicUsecase) UpdateComic(id uuid.UUID, creatorID uuid.UUID, input Upda
 	// comic.DefaultUnlockType = input.DefaultUnlockType
 	comic.UpdatedAt = time.Now()
 
-	if err := u.comicRepo.UpdateComic(comic); err != nil {
+	// Tags are resolved first so a failure there leaves the comic untouched.
+	var tags []domain.Tag
+	if input.Tags != nil {
+		tags, err = u.resolveTags(*input.Tags)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if err := u.comicRepo.UpdateComic(comic, tags); err != nil {
 		return nil, err
 	}
+	if tags != nil {
+		comic.Tags = tags
+	}
 
 	return comic, nil
 }
 
+// resolveTags maps tag names to stored tags, reusing a tag when its slug
+// already exists. The slug comes from the English name, or the Thai name
+// when there is no usable English one. Names that repeat a slug are merged
+// and empty names are ignored.
+func (u *comicUsecase) resolveTags(names []domain.MultilingualText) ([]domain.Tag, error) {
+	var tags []domain.Tag
+	seen := make(map[string]int)
+	for _, t := range names {
+		en, th := strings.TrimSpace(t.En), strings.TrimSpace(t.Th)
+		slug := utils.SimpleSlug(en)
+		if slug == "" {
+			slug = utils.UnicodeSlug(th)
+		}
+		if slug == "" {
+			continue
+		}
+
+		var translations []domain.TagTranslation
+		if en != "" {
+			translations = append(translations, domain.TagTranslation{Language: "en", Name: en})
+		}
+		if th != "" {
+			translations = append(translations, domain.TagTranslation{Language: "th", Name: th})
+		}
+
+		if i, ok := seen[slug]; ok {
+			tags[i].Translations = append(tags[i].Translations, translations...)
+			continue
+		}
+		seen[slug] = len(tags)
+		tags = append(tags, domain.Tag{Slug: slug, Translations: translations})
+	}
+
+	if len(tags) == 0 {
+		return []domain.Tag{}, nil
+	}
+	return u.tagRepo.FindOrCreate(tags)
+}
+
 func (u *comicUsecase) DeleteComic(id uuid.UUID, creatorID uuid.UUID) error {
 	comic, err := u.comicRepo.GetComicByID(id)
 	if err != nil {
@@ -0,0 +1,204 @@
+package usecase
+
+import (
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/pur108/talestoon-be/internal/domain"
+)
+
+var ErrFollowSelf = errors.New("you cannot follow yourself")
+
+// Following lists the creators and comics a user follows.
+type Following struct {
+	Creators []*domain.PublicProfile `json:"creators"`
+	Comics   []domain.Comic          `json:"comics"`
+}
+
+// FollowStatus is the state after a follow or subscription change.
+type FollowStatus struct {
+	Following     bool  `json:"following"`
+	FollowerCount int64 `json:"follower_count"`
+}
+
+type FollowUsecase interface {
+	FollowCreator(userID uuid.UUID, username string) (*FollowStatus, error)
+	UnfollowCreator(userID uuid.UUID, username string) (*FollowStatus, error)
+	SubscribeComic(viewer *domain.Principal, comicID uuid.UUID) (*FollowStatus, error)
+	UnsubscribeComic(viewer *domain.Principal, comicID uuid.UUID) (*FollowStatus, error)
+	ListFollowing(userID uuid.UUID) (*Following, error)
+}
+
+type followUsecase struct {
+	followRepo          domain.FollowRepository
+	userRepo            domain.UserRepository
+	comicRepo           domain.ComicRepository
+	notificationRepo    domain.NotificationRepository
+	notificationUsecase NotificationUsecase
+}
+
+func NewFollowUsecase(followRepo domain.FollowRepository, userRepo domain.UserRepository, comicRepo domain.ComicRepository, notificationRepo domain.NotificationRepository, notificationUsecase NotificationUsecase) FollowUsecase {
+	return &followUsecase{followRepo, userRepo, comicRepo, notificationRepo, notificationUsecase}
+}
+
+func (u *followUsecase) FollowCreator(userID uuid.UUID, username string) (*FollowStatus, error) {
+	creator, err := u.findCreator(username)
+	if err != nil {
+		return nil, err
+	}
+
+	if creator.ID == userID {
+		return nil, ErrFollowSelf
+	}
+
+	created, err := u.followRepo.FollowCreator(userID, creator.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	if created {
+		u.notifyNewFollower(userID, creator.ID)
+	}
+
+	return u.creatorStatus(creator.ID, true)
+}
+
+func (u *followUsecase) UnfollowCreator(userID uuid.UUID, username string) (*FollowStatus, error) {
+	creator, err := u.userRepo.FindByUsername(username)
+	if err != nil {
+		return nil, domain.ErrNotFound
+	}
+
+	if _, err := u.followRepo.UnfollowCreator(userID, creator.ID); err != nil {
+		return nil, err
+	}
+
+	return u.creatorStatus(creator.ID, false)
+}
+
+// notifyNewFollower tells the creator about a follower the first time they
+// follow, so unfollowing and following again does not notify them each time.
+func (u *followUsecase) notifyNewFollower(followerID, creatorID uuid.UUID) {
+	notified, err := u.notificationRepo.HasFollowerNotification(creatorID, followerID)
+	if err != nil || notified {
+		return
+	}
+
+	follower, err := u.userRepo.FindByID(followerID)
+	if err != nil {
+		return
+	}
+
+	name := follower.DisplayName
+	if name == "" {
+		name = follower.Username
+	}
+
+	u.notificationUsecase.Notify(domain.NotificationNewFollower, []uuid.UUID{creatorID}, name+" started following you", "", map[string]interface{}{
+		"follower_id": follower.ID,
+		"username":    follower.Username,
+	})
+}
+
+// findCreator returns the creator behind username if they can be followed:
+// a creator or admin whose account is neither banned nor deleted.
+func (u *followUsecase) findCreator(username string) (*domain.User, error) {
+	user, err := u.userRepo.FindByUsername(username)
+	if err != nil || (user.Role != domain.RoleCreator && user.Role != domain.RoleAdmin) || isBanned(user) || user.AnonymizedAt != nil {
+		return nil, domain.ErrNotFound
+	}
+	return user, nil
+}
+
+func (u *followUsecase) creatorStatus(creatorID uuid.UUID, following bool) (*FollowStatus, error) {
+	creator, err := u.userRepo.FindByID(creatorID)
+	if err != nil {
+		return nil, err
+	}
+	return &FollowStatus{Following: following, FollowerCount: creator.FollowerCount}, nil
+}
+
+// SubscribeComic subscribes the viewer to a comic they are allowed to read.
+// Comics of banned creators are hidden, as they are from GetComic.
+func (u *followUsecase) SubscribeComic(viewer *domain.Principal, comicID uuid.UUID) (*FollowStatus, error) {
+	comic, err := u.comicRepo.GetComicByID(comicID)
+	if err != nil || !canViewComic(comic, viewer) {
+		return nil, domain.ErrNotFound
+	}
+
+	if !isOwnerOrAdmin(comic, viewer) {
+		creator, err := u.userRepo.FindByID(comic.CreatorID)
+		if err == nil && isBanned(creator) {
+			return nil, domain.ErrNotFound
+		}
+	}
+
+	if comic.NSFW && !isOwnerOrAdmin(comic, viewer) {
+		user, err := u.userRepo.FindByID(viewer.UserID)
+		if err != nil {
+			return nil, err
+		}
+		if !user.IsAdult(time.Now()) {
+			return nil, ErrAgeRestricted
+		}
+	}
+
+	if _, err := u.followRepo.Subscribe(viewer.UserID, comic.ID); err != nil {
+		return nil, err
+	}
+
+	return u.comicStatus(comic.ID, true)
+}
+
+func (u *followUsecase) UnsubscribeComic(viewer *domain.Principal, comicID uuid.UUID) (*FollowStatus, error) {
+	if _, err := u.followRepo.Unsubscribe(viewer.UserID, comicID); err != nil {
+		return nil, err
+	}
+
+	return u.comicStatus(comicID, false)
+}
+
+func (u *followUsecase) comicStatus(comicID uuid.UUID, following bool) (*FollowStatus, error) {
+	comic, err := u.comicRepo.GetComicByID(comicID)
+	if err != nil {
+		return nil, domain.ErrNotFound
+	}
+	return &FollowStatus{Following: following, FollowerCount: comic.FollowerCount}, nil
+}
+
+// ListFollowing returns the creators and comics the user follows. NSFW comics
+// are redacted unless the user is a confirmed adult.
+func (u *followUsecase) ListFollowing(userID uuid.UUID) (*Following, error) {
+	user, err := u.userRepo.FindByID(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	creators, err := u.followRepo.ListFollowedCreators(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	comics, err := u.followRepo.ListSubscribedComics(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	following := &Following{
+		Creators: make([]*domain.PublicProfile, 0, len(creators)),
+		Comics:   comics,
+	}
+	for i := range creators {
+		following.Creators = append(following.Creators, creators[i].PublicProfile())
+	}
+
+	adult := user.IsAdult(time.Now())
+	for i := range following.Comics {
+		if following.Comics[i].NSFW && !adult && following.Comics[i].CreatorID != userID {
+			following.Comics[i].Redact()
+		}
+	}
+
+	return following, nil
+}
@@ -0,0 +1,95 @@
+package usecase
+
+import (
+	"html"
+	"regexp"
+	"sort"
+	"strings"
+	"unicode/utf8"
+)
+
+const (
+	// snippetLength is roughly how many bytes of a long field are kept
+	// around the first match.
+	snippetLength  = 160
+	snippetContext = 60
+)
+
+// highlighter marks the query terms found in result text with <mark> tags.
+// The text is HTML-escaped so the output can be rendered as-is.
+type highlighter struct {
+	pattern *regexp.Regexp
+}
+
+// newHighlighter matches the whole query and each of its words, longest
+// first, case-insensitively. Matching the whole query covers Thai, which has
+// no spaces between words.
+func newHighlighter(query string) *highlighter {
+	terms := append(strings.Fields(query), strings.TrimSpace(query))
+	sort.Slice(terms, func(i, j int) bool { return len(terms[i]) > len(terms[j]) })
+
+	quoted := make([]string, 0, len(terms))
+	seen := make(map[string]bool, len(terms))
+	for _, term := range terms {
+		key := strings.ToLower(term)
+		if term == "" || seen[key] {
+			continue
+		}
+		seen[key] = true
+		quoted = append(quoted, regexp.QuoteMeta(term))
+	}
+	if len(quoted) == 0 {
+		return &highlighter{}
+	}
+
+	return &highlighter{pattern: regexp.MustCompile("(?i)" + strings.Join(quoted, "|"))}
+}
+
+// Highlight returns text with its matches marked, trimmed to a snippet around
+// the first match when text is long. It reports false when nothing matched.
+func (h *highlighter) Highlight(text string) (string, bool) {
+	if h.pattern == nil || text == "" {
+		return "", false
+	}
+
+	matches := h.pattern.FindAllStringIndex(text, -1)
+	if len(matches) == 0 {
+		return "", false
+	}
+
+	start, end := 0, len(text)
+	if len(text) > snippetLength {
+		start = runeBoundary(text, max(0, matches[0][0]-snippetContext))
+		end = runeBoundary(text, min(len(text), start+snippetLength))
+	}
+
+	var b strings.Builder
+	if start > 0 {
+		b.WriteString("…")
+	}
+	pos := start
+	for _, match := range matches {
+		if match[0] < pos || match[1] > end {
+			continue
+		}
+		b.WriteString(html.EscapeString(text[pos:match[0]]))
+		b.WriteString("<mark>")
+		b.WriteString(html.EscapeString(text[match[0]:match[1]]))
+		b.WriteString("</mark>")
+		pos = match[1]
+	}
+	b.WriteString(html.EscapeString(text[pos:end]))
+	if end < len(text) {
+		b.WriteString("…")
+	}
+
+	return b.String(), true
+}
+
+// runeBoundary moves i back to the start of the rune it falls in.
+func runeBoundary(s string, i int) int {
+	for i > 0 && i < len(s) && !utf8.RuneStart(s[i]) {
+		i--
+	}
+	return i
+}
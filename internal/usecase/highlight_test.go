@@ -0,0 +1,44 @@
+package usecase
+
+import (
+	"strings"
+	"testing"
+	"unicode/utf8"
+)
+
+func TestHighlighter(t *testing.T) {
+	tests := []struct {
+		name  string
+		query string
+		text  string
+		want  string
+		found bool
+	}{
+		{"case insensitive words", "dragon king", "The Dragon's <King>", "The <mark>Dragon</mark>&#39;s &lt;<mark>King</mark>&gt;", true},
+		{"thai phrase", "ราชา", "ตำนานราชามังกร", "ตำนาน<mark>ราชา</mark>มังกร", true},
+		{"no match", "ghost", "The Dragon King", "", false},
+		{"regexp characters", "c++", "Learning C++ daily", "Learning <mark>C++</mark> daily", true},
+	}
+
+	for _, tt := range tests {
+		got, found := newHighlighter(tt.query).Highlight(tt.text)
+		if got != tt.want || found != tt.found {
+			t.Errorf("%s: Highlight(%q) = %q, %v; want %q, %v", tt.name, tt.text, got, found, tt.want, tt.found)
+		}
+	}
+}
+
+func TestHighlighterSnippet(t *testing.T) {
+	text := strings.Repeat("มังกร ", 30) + "ราชา"
+
+	got, found := newHighlighter("ราชา").Highlight(text)
+	if !found {
+		t.Fatal("expected a match")
+	}
+	if !strings.HasPrefix(got, "…") {
+		t.Errorf("snippet should start with an ellipsis, got %q", got)
+	}
+	if !utf8.ValidString(got) {
+		t.Errorf("snippet split a rune: %q", got)
+	}
+}
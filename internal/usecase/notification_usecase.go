@@ -0,0 +1,143 @@
+package usecase
+
+import (
+	"errors"
+	"log"
+	"slices"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/pur108/talestoon-be/internal/domain"
+)
+
+const (
+	defaultNotificationPageSize = 20
+	maxNotificationPageSize     = 100
+	maxMarkReadIDs              = 100
+)
+
+// NotificationPage is one page of a user's notifications. NextCursor is
+// empty on the last page.
+type NotificationPage struct {
+	Data        []domain.Notification `json:"data"`
+	UnreadCount int64                 `json:"unread_count"`
+	NextCursor  string                `json:"next_cursor,omitempty"`
+}
+
+type NotificationUsecase interface {
+	List(userID uuid.UUID, filter domain.NotificationFilter) (*NotificationPage, error)
+	UnreadCount(userID uuid.UUID) (int64, error)
+	MarkRead(userID uuid.UUID, ids []uuid.UUID) (int64, error)
+	MarkAllRead(userID uuid.UUID) (int64, error)
+	GetPreferences(userID uuid.UUID) (map[domain.NotificationType]bool, error)
+	UpdatePreferences(userID uuid.UUID, changes map[domain.NotificationType]bool) (map[domain.NotificationType]bool, error)
+	// Notify sends a notification to every user in userIDs that has not
+	// switched its type off. It is best effort: failures are logged and never
+	// fail the action that triggered it.
+	Notify(notificationType domain.NotificationType, userIDs []uuid.UUID, title, body string, data map[string]interface{})
+}
+
+type notificationUsecase struct {
+	notificationRepo domain.NotificationRepository
+}
+
+func NewNotificationUsecase(notificationRepo domain.NotificationRepository) NotificationUsecase {
+	return &notificationUsecase{notificationRepo}
+}
+
+func (u *notificationUsecase) List(userID uuid.UUID, filter domain.NotificationFilter) (*NotificationPage, error) {
+	if filter.Limit <= 0 || filter.Limit > maxNotificationPageSize {
+		filter.Limit = defaultNotificationPageSize
+	}
+
+	notifications, next, err := u.notificationRepo.List(userID, filter)
+	if err != nil {
+		return nil, err
+	}
+
+	unread, err := u.notificationRepo.CountUnread(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	return &NotificationPage{Data: notifications, UnreadCount: unread, NextCursor: next}, nil
+}
+
+func (u *notificationUsecase) UnreadCount(userID uuid.UUID) (int64, error) {
+	return u.notificationRepo.CountUnread(userID)
+}
+
+func (u *notificationUsecase) MarkRead(userID uuid.UUID, ids []uuid.UUID) (int64, error) {
+	if len(ids) == 0 {
+		return 0, errors.New("at least one notification ID is required")
+	}
+	if len(ids) > maxMarkReadIDs {
+		return 0, errors.New("at most 100 notifications can be marked at once")
+	}
+	return u.notificationRepo.MarkRead(userID, ids, time.Now())
+}
+
+func (u *notificationUsecase) MarkAllRead(userID uuid.UUID) (int64, error) {
+	return u.notificationRepo.MarkAllRead(userID, time.Now())
+}
+
+// GetPreferences returns whether each notification type is enabled.
+func (u *notificationUsecase) GetPreferences(userID uuid.UUID) (map[domain.NotificationType]bool, error) {
+	stored, err := u.notificationRepo.ListPreferences(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	preferences := make(map[domain.NotificationType]bool, len(domain.NotificationTypes))
+	for _, notificationType := range domain.NotificationTypes {
+		preferences[notificationType] = true
+	}
+	for _, preference := range stored {
+		if _, known := preferences[preference.Type]; known {
+			preferences[preference.Type] = preference.Enabled
+		}
+	}
+	return preferences, nil
+}
+
+func (u *notificationUsecase) UpdatePreferences(userID uuid.UUID, changes map[domain.NotificationType]bool) (map[domain.NotificationType]bool, error) {
+	updates := make([]domain.NotificationPreference, 0, len(changes))
+	for notificationType, enabled := range changes {
+		if !slices.Contains(domain.NotificationTypes, notificationType) {
+			return nil, errors.New("unknown notification type: " + string(notificationType))
+		}
+		updates = append(updates, domain.NotificationPreference{UserID: userID, Type: notificationType, Enabled: enabled})
+	}
+
+	if err := u.notificationRepo.SavePreferences(updates); err != nil {
+		return nil, err
+	}
+
+	return u.GetPreferences(userID)
+}
+
+func (u *notificationUsecase) Notify(notificationType domain.NotificationType, userIDs []uuid.UUID, title, body string, data map[string]interface{}) {
+	recipients, err := u.notificationRepo.FilterRecipients(userIDs, notificationType)
+	if err != nil {
+		log.Printf("failed to resolve %s notification recipients: %v", notificationType, err)
+		return
+	}
+
+	now := time.Now()
+	notifications := make([]domain.Notification, 0, len(recipients))
+	for _, userID := range recipients {
+		notifications = append(notifications, domain.Notification{
+			ID:        uuid.New(),
+			UserID:    userID,
+			Type:      notificationType,
+			Title:     title,
+			Body:      body,
+			Data:      data,
+			CreatedAt: now,
+		})
+	}
+
+	if err := u.notificationRepo.CreateMany(notifications); err != nil {
+		log.Printf("failed to create %d %s notifications: %v", len(notifications), notificationType, err)
+	}
+}
@@ -0,0 +1,302 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/rand/v2"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/pur108/talestoon-be/internal/domain"
+	"github.com/pur108/talestoon-be/internal/oidc"
+	"github.com/pur108/talestoon-be/pkg/utils"
+)
+
+const oidcStateTTL = 10 * time.Minute
+
+var (
+	ErrUnknownProvider     = errors.New("unknown identity provider")
+	ErrIdentityLinked      = errors.New("this identity is already linked to another account")
+	ErrOIDCEmailRequired   = errors.New("identity provider did not return an email address")
+	ErrOIDCAccountExists   = errors.New("an account with this email already exists; log in and link the provider from your account settings")
+	ErrLastLoginMethod     = errors.New("cannot unlink the only way to sign in; set a password first")
+	usernameDisallowedChar = regexp.MustCompile(`[^a-z0-9_]+`)
+)
+
+// OIDCResult is the outcome of a provider callback: a login for the
+// identity's user, or the identity newly linked to the signed-in user.
+type OIDCResult struct {
+	Login  *LoginResult
+	Linked *domain.ExternalIdentity
+}
+
+type OIDCUsecase interface {
+	Providers() []string
+	BeginLogin(provider string, linkUserID *uuid.UUID) (string, error)
+	CompleteLogin(provider, state, code string, client ClientInfo) (*OIDCResult, error)
+	ListIdentities(userID uuid.UUID) ([]domain.ExternalIdentity, error)
+	UnlinkIdentity(userID, identityID uuid.UUID) error
+}
+
+type oidcUsecase struct {
+	identityRepo domain.IdentityRepository
+	userRepo     domain.UserRepository
+	authUsecase  AuthUsecase
+	providers    map[string]*oidc.Provider
+}
+
+func NewOIDCUsecase(identityRepo domain.IdentityRepository, userRepo domain.UserRepository, authUsecase AuthUsecase, providers map[string]*oidc.Provider) OIDCUsecase {
+	return &oidcUsecase{identityRepo, userRepo, authUsecase, providers}
+}
+
+func (u *oidcUsecase) Providers() []string {
+	names := make([]string, 0, len(u.providers))
+	for name := range u.providers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// BeginLogin stores a fresh state, nonce and PKCE verifier and returns the
+// provider URL to redirect the user to.
+func (u *oidcUsecase) BeginLogin(provider string, linkUserID *uuid.UUID) (string, error) {
+	p, ok := u.providers[provider]
+	if !ok {
+		return "", ErrUnknownProvider
+	}
+
+	state, err := utils.GenerateToken(32)
+	if err != nil {
+		return "", err
+	}
+	nonce, err := utils.GenerateToken(16)
+	if err != nil {
+		return "", err
+	}
+	verifier, err := oidc.NewCodeVerifier()
+	if err != nil {
+		return "", err
+	}
+
+	authURL, err := p.AuthCodeURL(context.Background(), state, nonce, verifier)
+	if err != nil {
+		return "", err
+	}
+
+	err = u.identityRepo.CreateLoginState(&domain.OIDCLoginState{
+		ID:           uuid.New(),
+		StateHash:    utils.HashToken(state),
+		Provider:     provider,
+		CodeVerifier: verifier,
+		Nonce:        nonce,
+		LinkUserID:   linkUserID,
+		ExpiresAt:    time.Now().Add(oidcStateTTL),
+		CreatedAt:    time.Now(),
+	})
+	if err != nil {
+		return "", err
+	}
+
+	return authURL, nil
+}
+
+// CompleteLogin redeems the authorization code returned to the callback.
+// Unknown identities are linked to an existing account only when both the
+// provider and our records have the email verified; otherwise a new account
+// is provisioned.
+func (u *oidcUsecase) CompleteLogin(provider, state, code string, client ClientInfo) (*OIDCResult, error) {
+	p, ok := u.providers[provider]
+	if !ok {
+		return nil, ErrUnknownProvider
+	}
+
+	loginState, err := u.identityRepo.ConsumeLoginState(utils.HashToken(state))
+	if err != nil {
+		return nil, domain.ErrInvalidToken
+	}
+	if loginState.Provider != provider || time.Now().After(loginState.ExpiresAt) {
+		return nil, domain.ErrInvalidToken
+	}
+
+	claims, err := p.Exchange(context.Background(), code, loginState.CodeVerifier, loginState.Nonce)
+	if err != nil {
+		return nil, err
+	}
+
+	identity, err := u.identityRepo.FindByProviderSubject(provider, claims.Subject)
+	if err != nil {
+		identity = nil
+	}
+
+	if loginState.LinkUserID != nil {
+		return u.link(*loginState.LinkUserID, provider, claims, identity)
+	}
+
+	var user *domain.User
+	if identity != nil {
+		user, err = u.userRepo.FindByID(identity.UserID)
+		if err != nil {
+			return nil, err
+		}
+		if err := u.identityRepo.TouchLastLogin(identity.ID, time.Now()); err != nil {
+			return nil, err
+		}
+	} else {
+		user, err = u.resolveUser(provider, claims)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	result, err := u.authUsecase.CompleteLogin(user, client)
+	if err != nil {
+		return nil, err
+	}
+
+	return &OIDCResult{Login: result}, nil
+}
+
+func (u *oidcUsecase) ListIdentities(userID uuid.UUID) ([]domain.ExternalIdentity, error) {
+	return u.identityRepo.ListByUserID(userID)
+}
+
+// UnlinkIdentity removes a linked identity unless it is the account's only
+// way to sign in.
+func (u *oidcUsecase) UnlinkIdentity(userID, identityID uuid.UUID) error {
+	user, err := u.userRepo.FindByID(userID)
+	if err != nil {
+		return err
+	}
+
+	if user.PasswordHash == "" {
+		identities, err := u.identityRepo.ListByUserID(userID)
+		if err != nil {
+			return err
+		}
+		if len(identities) <= 1 {
+			return ErrLastLoginMethod
+		}
+	}
+
+	return u.identityRepo.Delete(identityID, userID)
+}
+
+func (u *oidcUsecase) link(userID uuid.UUID, provider string, claims *oidc.Claims, existing *domain.ExternalIdentity) (*OIDCResult, error) {
+	if existing != nil {
+		if existing.UserID != userID {
+			return nil, ErrIdentityLinked
+		}
+		return &OIDCResult{Linked: existing}, nil
+	}
+
+	identity, err := u.createIdentity(userID, provider, claims)
+	if err != nil {
+		return nil, err
+	}
+
+	return &OIDCResult{Linked: identity}, nil
+}
+
+// resolveUser finds or provisions the account for an identity seen for the
+// first time.
+func (u *oidcUsecase) resolveUser(provider string, claims *oidc.Claims) (*domain.User, error) {
+	email := strings.ToLower(strings.TrimSpace(claims.Email))
+	if email == "" {
+		return nil, ErrOIDCEmailRequired
+	}
+
+	user, err := u.userRepo.FindByEmail(email)
+	if err == nil {
+		if !claims.EmailVerified || user.EmailVerifiedAt == nil {
+			return nil, ErrOIDCAccountExists
+		}
+	} else {
+		user, err = u.provisionUser(email, claims)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if _, err := u.createIdentity(user.ID, provider, claims); err != nil {
+		return nil, err
+	}
+
+	return user, nil
+}
+
+// provisionUser creates an account without a password. Its owner signs in
+// through the provider or sets a password with the reset flow.
+func (u *oidcUsecase) provisionUser(email string, claims *oidc.Claims) (*domain.User, error) {
+	username, err := u.availableUsername(claims.PreferredUsername, email)
+	if err != nil {
+		return nil, err
+	}
+
+	user := &domain.User{
+		ID:          uuid.New(),
+		Username:    username,
+		Email:       email,
+		Role:        domain.RoleUser,
+		DisplayName: claims.Name,
+	}
+	if claims.EmailVerified {
+		now := time.Now()
+		user.EmailVerifiedAt = &now
+	}
+
+	if err := u.userRepo.Create(user); err != nil {
+		return nil, err
+	}
+
+	return user, nil
+}
+
+func (u *oidcUsecase) createIdentity(userID uuid.UUID, provider string, claims *oidc.Claims) (*domain.ExternalIdentity, error) {
+	now := time.Now()
+	identity := &domain.ExternalIdentity{
+		ID:          uuid.New(),
+		UserID:      userID,
+		Provider:    provider,
+		Subject:     claims.Subject,
+		Email:       claims.Email,
+		LastLoginAt: &now,
+		CreatedAt:   now,
+	}
+
+	if err := u.identityRepo.Create(identity); err != nil {
+		return nil, err
+	}
+
+	return identity, nil
+}
+
+// availableUsername derives a username from the provider's preferred
+// username or the email's local part, adding a numeric suffix on collision.
+func (u *oidcUsecase) availableUsername(preferred, email string) (string, error) {
+	base := preferred
+	if base == "" {
+		base = strings.SplitN(email, "@", 2)[0]
+	}
+	base = usernameDisallowedChar.ReplaceAllString(strings.ToLower(base), "")
+	if len(base) > 20 {
+		base = base[:20]
+	}
+	if len(base) < 3 {
+		base = "reader"
+	}
+
+	candidate := base
+	for i := 0; i < 5; i++ {
+		if existing, _ := u.userRepo.FindByEmailOrUsername(candidate); existing == nil {
+			return candidate, nil
+		}
+		candidate = fmt.Sprintf("%s_%d", base, rand.IntN(10000))
+	}
+
+	return "", errors.New("could not find an available username")
+}
@@ -0,0 +1,178 @@
+package usecase
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/pur108/talestoon-be/internal/domain"
+)
+
+type RoleUsecase interface {
+	RequestCreator(userID uuid.UUID, message string) (*domain.RoleRequest, error)
+	ListMyRequests(userID uuid.UUID) ([]domain.RoleRequest, error)
+	ListRequests(status domain.RoleRequestStatus) ([]domain.RoleRequest, error)
+	ApproveRequest(id uuid.UUID, adminID uuid.UUID, note string) (*domain.RoleRequest, error)
+	DenyRequest(id uuid.UUID, adminID uuid.UUID, note string) (*domain.RoleRequest, error)
+	ChangeRole(userID uuid.UUID, role domain.UserRole, adminID uuid.UUID, reason string) error
+	ListRoleHistory(userID uuid.UUID) ([]domain.RoleChange, error)
+}
+
+type roleUsecase struct {
+	roleRepo            domain.RoleRepository
+	userRepo            domain.UserRepository
+	notificationUsecase NotificationUsecase
+}
+
+func NewRoleUsecase(roleRepo domain.RoleRepository, userRepo domain.UserRepository, notificationUsecase NotificationUsecase) RoleUsecase {
+	return &roleUsecase{roleRepo, userRepo, notificationUsecase}
+}
+
+func (u *roleUsecase) RequestCreator(userID uuid.UUID, message string) (*domain.RoleRequest, error) {
+	user, err := u.userRepo.FindByID(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	if user.Role == domain.RoleCreator || user.Role == domain.RoleAdmin {
+		return nil, errors.New("user is already a creator or admin")
+	}
+
+	if pending, _ := u.roleRepo.FindPendingRequestByUserID(userID); pending != nil {
+		return nil, errors.New("a creator request is already pending")
+	}
+
+	request := &domain.RoleRequest{
+		ID:            uuid.New(),
+		UserID:        userID,
+		RequestedRole: domain.RoleCreator,
+		Status:        domain.RoleRequestPending,
+		Message:       message,
+		CreatedAt:     time.Now(),
+		UpdatedAt:     time.Now(),
+	}
+
+	if err := u.roleRepo.CreateRequest(request); err != nil {
+		return nil, err
+	}
+
+	return request, nil
+}
+
+func (u *roleUsecase) ListMyRequests(userID uuid.UUID) ([]domain.RoleRequest, error) {
+	return u.roleRepo.ListRequestsByUserID(userID)
+}
+
+func (u *roleUsecase) ListRequests(status domain.RoleRequestStatus) ([]domain.RoleRequest, error) {
+	return u.roleRepo.ListRequests(status)
+}
+
+func (u *roleUsecase) ApproveRequest(id uuid.UUID, adminID uuid.UUID, note string) (*domain.RoleRequest, error) {
+	request, err := u.pendingRequest(id)
+	if err != nil {
+		return nil, err
+	}
+
+	user, err := u.userRepo.FindByID(request.UserID)
+	if err != nil {
+		return nil, err
+	}
+
+	// Only plain users are promoted. Someone who became a creator or an
+	// admin while the request was pending keeps their role, and the request
+	// is simply closed as approved.
+	var change *domain.RoleChange
+	if user.Role == domain.RoleUser {
+		change = &domain.RoleChange{
+			ID:        uuid.New(),
+			UserID:    user.ID,
+			OldRole:   user.Role,
+			NewRole:   request.RequestedRole,
+			ChangedBy: adminID,
+			RequestID: &request.ID,
+			Reason:    note,
+			CreatedAt: time.Now(),
+		}
+	}
+
+	return request, u.review(request, domain.RoleRequestApproved, adminID, note, change)
+}
+
+func (u *roleUsecase) DenyRequest(id uuid.UUID, adminID uuid.UUID, note string) (*domain.RoleRequest, error) {
+	request, err := u.pendingRequest(id)
+	if err != nil {
+		return nil, err
+	}
+
+	return request, u.review(request, domain.RoleRequestDenied, adminID, note, nil)
+}
+
+func (u *roleUsecase) ChangeRole(userID uuid.UUID, role domain.UserRole, adminID uuid.UUID, reason string) error {
+	if role != domain.RoleUser && role != domain.RoleCreator && role != domain.RoleAdmin {
+		return errors.New("invalid role")
+	}
+
+	if userID == adminID {
+		return errors.New("admins cannot change their own role")
+	}
+
+	user, err := u.userRepo.FindByID(userID)
+	if err != nil {
+		return err
+	}
+
+	if user.Role == role {
+		return nil
+	}
+
+	return u.roleRepo.ApplyRoleChange(&domain.RoleChange{
+		ID:        uuid.New(),
+		UserID:    user.ID,
+		OldRole:   user.Role,
+		NewRole:   role,
+		ChangedBy: adminID,
+		Reason:    reason,
+		CreatedAt: time.Now(),
+	})
+}
+
+func (u *roleUsecase) ListRoleHistory(userID uuid.UUID) ([]domain.RoleChange, error) {
+	return u.roleRepo.ListRoleChanges(userID)
+}
+
+func (u *roleUsecase) pendingRequest(id uuid.UUID) (*domain.RoleRequest, error) {
+	request, err := u.roleRepo.FindRequestByID(id)
+	if err != nil {
+		return nil, domain.ErrNotFound
+	}
+
+	if request.Status != domain.RoleRequestPending {
+		return nil, domain.ErrRequestReviewed
+	}
+
+	return request, nil
+}
+
+// review records the decision on a pending request together with the role
+// change it causes, if any.
+func (u *roleUsecase) review(request *domain.RoleRequest, status domain.RoleRequestStatus, adminID uuid.UUID, note string, change *domain.RoleChange) error {
+	now := time.Now()
+	request.Status = status
+	request.ReviewNote = note
+	request.ReviewedBy = &adminID
+	request.ReviewedAt = &now
+	request.UpdatedAt = now
+
+	if err := u.roleRepo.ReviewRequest(request, change); err != nil {
+		return err
+	}
+
+	title := fmt.Sprintf("Your %s request was %s", request.RequestedRole, status)
+	u.notificationUsecase.Notify(domain.NotificationModerationResult, []uuid.UUID{request.UserID}, title, note, map[string]interface{}{
+		"role_request_id": request.ID,
+		"status":          status,
+	})
+
+	return nil
+}
@@ -0,0 +1,76 @@
+package usecase
+
+import (
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/pur108/talestoon-be/internal/domain"
+)
+
+type fakeRoleRepository struct {
+	domain.RoleRepository
+	request *domain.RoleRequest
+	change  *domain.RoleChange
+}
+
+func (r *fakeRoleRepository) FindRequestByID(id uuid.UUID) (*domain.RoleRequest, error) {
+	return r.request, nil
+}
+
+func (r *fakeRoleRepository) ReviewRequest(request *domain.RoleRequest, change *domain.RoleChange) error {
+	r.request = request
+	r.change = change
+	return nil
+}
+
+type fakeUserRepository struct {
+	domain.UserRepository
+	user *domain.User
+}
+
+func (r *fakeUserRepository) FindByID(id uuid.UUID) (*domain.User, error) {
+	return r.user, nil
+}
+
+type fakeNotificationUsecase struct {
+	NotificationUsecase
+}
+
+func (fakeNotificationUsecase) Notify(domain.NotificationType, []uuid.UUID, string, string, map[string]interface{}) {
+}
+
+func TestApproveRequestRoles(t *testing.T) {
+	tests := []struct {
+		role       domain.UserRole
+		wantChange bool
+	}{
+		{domain.RoleUser, true},
+		{domain.RoleCreator, false},
+		{domain.RoleAdmin, false},
+	}
+
+	for _, tt := range tests {
+		user := &domain.User{ID: uuid.New(), Role: tt.role}
+		roleRepo := &fakeRoleRepository{request: &domain.RoleRequest{
+			ID:            uuid.New(),
+			UserID:        user.ID,
+			RequestedRole: domain.RoleCreator,
+			Status:        domain.RoleRequestPending,
+		}}
+		u := NewRoleUsecase(roleRepo, &fakeUserRepository{user: user}, fakeNotificationUsecase{})
+
+		request, err := u.ApproveRequest(roleRepo.request.ID, uuid.New(), "")
+		if err != nil {
+			t.Fatalf("%s: ApproveRequest: %v", tt.role, err)
+		}
+		if request.Status != domain.RoleRequestApproved {
+			t.Errorf("%s: status = %s, want %s", tt.role, request.Status, domain.RoleRequestApproved)
+		}
+		if got := roleRepo.change != nil; got != tt.wantChange {
+			t.Errorf("%s: role change applied = %v, want %v", tt.role, got, tt.wantChange)
+		}
+		if roleRepo.change != nil && roleRepo.change.NewRole != domain.RoleCreator {
+			t.Errorf("%s: new role = %s, want %s", tt.role, roleRepo.change.NewRole, domain.RoleCreator)
+		}
+	}
+}
@@ -0,0 +1,241 @@
+package usecase
+
+import (
+	"errors"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/google/uuid"
+	"github.com/pur108/talestoon-be/internal/domain"
+)
+
+const (
+	minSearchQueryLength  = 2
+	maxSearchQueryLength  = 100
+	defaultSearchPageSize = 20
+	maxSearchPageSize     = 50
+
+	maxSuggestQueryLength  = 50
+	defaultSuggestionLimit = 5
+	maxSuggestionLimit     = 10
+)
+
+var (
+	ErrInvalidSearchQuery  = errors.New("search query must be 2 to 100 characters")
+	ErrInvalidSuggestQuery = errors.New("suggestion query must be 1 to 50 characters")
+)
+
+// SearchResult is a matching comic with its highlighted fields, keyed by
+// field and language, e.g. "title.en" or "description.th", plus "author" and
+// "tags".
+type SearchResult struct {
+	Comic      domain.Comic      `json:"comic"`
+	Highlights map[string]string `json:"highlights"`
+}
+
+type SearchPage struct {
+	Query    string         `json:"query"`
+	Data     []SearchResult `json:"data"`
+	Total    int64          `json:"total"`
+	Page     int            `json:"page"`
+	PageSize int            `json:"page_size"`
+}
+
+// Suggestions are typeahead completions in the reader's language.
+type Suggestions struct {
+	Query   string            `json:"query"`
+	Comics  []ComicSuggestion `json:"comics"`
+	Authors []string          `json:"authors"`
+	Tags    []TagSuggestion   `json:"tags"`
+}
+
+type ComicSuggestion struct {
+	ID            uuid.UUID `json:"id"`
+	Title         string    `json:"title"`
+	CoverImageURL string    `json:"cover_image_url"`
+}
+
+type TagSuggestion struct {
+	Slug string `json:"slug"`
+	Name string `json:"name"`
+}
+
+type SearchUsecase interface {
+	Search(viewer *domain.Principal, query string, page, pageSize int) (*SearchPage, error)
+	Suggest(viewer *domain.Principal, query string, limit int) (*Suggestions, error)
+}
+
+type searchUsecase struct {
+	searchRepo      domain.SearchRepository
+	userRepo        domain.UserRepository
+	preferencesRepo domain.PreferencesRepository
+}
+
+func NewSearchUsecase(searchRepo domain.SearchRepository, userRepo domain.UserRepository, preferencesRepo domain.PreferencesRepository) SearchUsecase {
+	return &searchUsecase{searchRepo, userRepo, preferencesRepo}
+}
+
+// Search finds public comics by title, subtitle, description, author and tag
+// name in either language. The viewer's content filters apply as they do to
+// the catalog listing.
+func (u *searchUsecase) Search(viewer *domain.Principal, query string, page, pageSize int) (*SearchPage, error) {
+	query = strings.Join(strings.Fields(query), " ")
+	if length := utf8.RuneCountInString(query); length < minSearchQueryLength || length > maxSearchQueryLength {
+		return nil, ErrInvalidSearchQuery
+	}
+
+	if page < 1 {
+		page = 1
+	}
+	if pageSize <= 0 || pageSize > maxSearchPageSize {
+		pageSize = defaultSearchPageSize
+	}
+
+	reader, err := loadReader(u.userRepo, u.preferencesRepo, viewer)
+	if err != nil {
+		return nil, err
+	}
+
+	filter := reader.catalogFilter()
+	filter.Limit = pageSize
+
+	comics, total, err := u.searchRepo.SearchComics(query, filter, (page-1)*pageSize)
+	if err != nil {
+		return nil, err
+	}
+
+	h := newHighlighter(query)
+	results := make([]SearchResult, 0, len(comics))
+	for i := range comics {
+		comics[i].Localize(reader.preferences.Locale)
+		results = append(results, SearchResult{Comic: comics[i], Highlights: highlightComic(h, &comics[i])})
+	}
+
+	return &SearchPage{Query: query, Data: results, Total: total, Page: page, PageSize: pageSize}, nil
+}
+
+// Suggest returns comic titles, authors and tag names starting with query.
+// Each is shown in the reader's language unless only the other language
+// matched what was typed.
+func (u *searchUsecase) Suggest(viewer *domain.Principal, query string, limit int) (*Suggestions, error) {
+	query = strings.TrimLeft(query, " ")
+	if length := utf8.RuneCountInString(query); length < 1 || length > maxSuggestQueryLength {
+		return nil, ErrInvalidSuggestQuery
+	}
+	prefix := strings.ToLower(query)
+
+	if limit <= 0 || limit > maxSuggestionLimit {
+		limit = defaultSuggestionLimit
+	}
+
+	reader, err := loadReader(u.userRepo, u.preferencesRepo, viewer)
+	if err != nil {
+		return nil, err
+	}
+	locale := reader.preferences.Locale
+
+	filter := reader.catalogFilter()
+	filter.Limit = limit
+
+	comics, err := u.searchRepo.SuggestComics(prefix, filter)
+	if err != nil {
+		return nil, err
+	}
+
+	authors, err := u.searchRepo.SuggestAuthors(prefix, filter)
+	if err != nil {
+		return nil, err
+	}
+
+	tags, err := u.searchRepo.SuggestTags(prefix, filter)
+	if err != nil {
+		return nil, err
+	}
+
+	suggestions := &Suggestions{
+		Query:   query,
+		Comics:  make([]ComicSuggestion, 0, len(comics)),
+		Authors: authors,
+		Tags:    make([]TagSuggestion, 0, len(tags)),
+	}
+
+	for _, comic := range comics {
+		title := comic.Title.In(locale)
+		if !hasPrefixFold(title, prefix) {
+			title = comic.Title.In(otherLocale(locale))
+		}
+		suggestions.Comics = append(suggestions.Comics, ComicSuggestion{ID: comic.ID, Title: title, CoverImageURL: comic.CoverImageURL})
+	}
+
+	for _, tag := range tags {
+		suggestions.Tags = append(suggestions.Tags, TagSuggestion{Slug: tag.Slug, Name: tagName(tag, locale, prefix)})
+	}
+
+	if suggestions.Authors == nil {
+		suggestions.Authors = []string{}
+	}
+	return suggestions, nil
+}
+
+// tagName picks the tag's name in locale, or the translation that matched
+// prefix when the preferred one does not.
+func tagName(tag domain.Tag, locale, prefix string) string {
+	var preferred, matched string
+	for _, translation := range tag.Translations {
+		if translation.Language == locale {
+			preferred = translation.Name
+		}
+		if matched == "" && hasPrefixFold(translation.Name, prefix) {
+			matched = translation.Name
+		}
+	}
+	if preferred != "" && hasPrefixFold(preferred, prefix) {
+		return preferred
+	}
+	if matched != "" {
+		return matched
+	}
+	return tag.Slug
+}
+
+func otherLocale(locale string) string {
+	if locale == domain.LocaleTh {
+		return domain.LocaleEn
+	}
+	return domain.LocaleTh
+}
+
+func hasPrefixFold(s, prefix string) bool {
+	return strings.HasPrefix(strings.ToLower(s), prefix)
+}
+
+func highlightComic(h *highlighter, comic *domain.Comic) map[string]string {
+	highlights := make(map[string]string)
+	add := func(key, text string) {
+		if marked, ok := h.Highlight(text); ok {
+			highlights[key] = marked
+		}
+	}
+
+	add("title.en", comic.Title.En)
+	add("title.th", comic.Title.Th)
+	add("subtitle.en", comic.Subtitle.En)
+	add("subtitle.th", comic.Subtitle.Th)
+	add("description.en", comic.Description.En)
+	add("description.th", comic.Description.Th)
+	add("author", comic.Author)
+
+	var tags []string
+	for _, tag := range comic.Tags {
+		for _, translation := range tag.Translations {
+			if marked, ok := h.Highlight(translation.Name); ok {
+				tags = append(tags, marked)
+			}
+		}
+	}
+	if len(tags) > 0 {
+		highlights["tags"] = strings.Join(tags, ", ")
+	}
+
+	return highlights
+}
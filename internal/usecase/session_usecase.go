@@ -0,0 +1,100 @@
+package usecase
+
+import (
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/pur108/talestoon-be/internal/domain"
+)
+
+type SessionUsecase interface {
+	ListSessions(userID, currentSessionID uuid.UUID) ([]domain.Session, error)
+	RevokeSession(userID, sessionID uuid.UUID) error
+	RevokeOtherSessions(userID, currentSessionID uuid.UUID) (int64, error)
+}
+
+type sessionUsecase struct {
+	sessionRepo domain.SessionRepository
+	tokenRepo   domain.TokenRepository
+}
+
+func NewSessionUsecase(sessionRepo domain.SessionRepository, tokenRepo domain.TokenRepository) SessionUsecase {
+	return &sessionUsecase{sessionRepo, tokenRepo}
+}
+
+// ListSessions returns the user's sessions that can still be refreshed,
+// most recently used first.
+func (u *sessionUsecase) ListSessions(userID, currentSessionID uuid.UUID) ([]domain.Session, error) {
+	sessions, err := u.sessionRepo.ListActiveByUserID(userID, time.Now().Add(-refreshTokenTTL))
+	if err != nil {
+		return nil, err
+	}
+
+	for i := range sessions {
+		sessions[i].Current = sessions[i].ID == currentSessionID
+	}
+
+	return sessions, nil
+}
+
+// RevokeSession signs a session out. Its access tokens stop validating on the
+// next request because Authenticate checks the session.
+func (u *sessionUsecase) RevokeSession(userID, sessionID uuid.UUID) error {
+	if err := u.sessionRepo.Revoke(sessionID, userID); err != nil {
+		return err
+	}
+	return u.tokenRepo.RevokeSessionRefreshTokens(sessionID)
+}
+
+func (u *sessionUsecase) RevokeOtherSessions(userID, currentSessionID uuid.UUID) (int64, error) {
+	return u.sessionRepo.RevokeOthers(userID, currentSessionID)
+}
+
+// describeDevice turns a user agent into a short label such as
+// "Chrome on Windows" for session listings.
+func describeDevice(userAgent string) string {
+	if userAgent == "" {
+		return "Unknown device"
+	}
+
+	var platform string
+	switch {
+	case strings.Contains(userAgent, "iPhone"):
+		platform = "iPhone"
+	case strings.Contains(userAgent, "iPad"):
+		platform = "iPad"
+	case strings.Contains(userAgent, "Android"):
+		platform = "Android"
+	case strings.Contains(userAgent, "Windows"):
+		platform = "Windows"
+	case strings.Contains(userAgent, "Macintosh"), strings.Contains(userAgent, "Mac OS X"):
+		platform = "macOS"
+	case strings.Contains(userAgent, "Linux"):
+		platform = "Linux"
+	}
+
+	var browser string
+	switch {
+	case strings.Contains(userAgent, "Edg/"):
+		browser = "Edge"
+	case strings.Contains(userAgent, "Firefox/"):
+		browser = "Firefox"
+	case strings.Contains(userAgent, "Chrome/"):
+		browser = "Chrome"
+	case strings.Contains(userAgent, "Safari/"):
+		browser = "Safari"
+	}
+
+	switch {
+	case browser != "" && platform != "":
+		return browser + " on " + platform
+	case browser != "":
+		return browser
+	case platform != "":
+		return platform
+	}
+
+	// Scripts and native clients: keep the product token, e.g. "curl/8.5".
+	return strings.SplitN(userAgent, " ", 2)[0]
+}
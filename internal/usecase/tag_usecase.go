@@ -0,0 +1,152 @@
+package usecase
+
+import (
+	"errors"
+	"regexp"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/google/uuid"
+	"github.com/pur108/talestoon-be/internal/domain"
+	"github.com/pur108/talestoon-be/pkg/utils"
+)
+
+const maxTagNameLength = 50
+
+// tagLanguagePattern accepts language codes with an optional region, such as
+// en, th or zh-tw.
+var tagLanguagePattern = regexp.MustCompile(`^[a-z]{2,3}(-[a-z0-9]{2,8})?$`)
+
+var (
+	ErrInvalidTagSlug = errors.New("tag slug must contain letters or digits")
+	// ErrTagSlugTaken is returned when renaming a tag onto another tag's
+	// slug. Such duplicates should be merged instead.
+	ErrTagSlugTaken       = errors.New("another tag already uses this slug; merge the tags instead")
+	ErrInvalidTagLanguage = errors.New("language must be a language code such as en, th or zh-tw")
+	ErrInvalidTagName     = errors.New("tag name must be 1 to 50 characters")
+	ErrMergeTagIntoItself = errors.New("cannot merge a tag into itself")
+)
+
+type TagUsecase interface {
+	ListTags(filter domain.TagFilter) ([]domain.Tag, int64, error)
+	GetTag(slug string) (*domain.Tag, error)
+	ListComics(viewer *domain.Principal, slug string, input ListComicsInput) (*domain.ComicPage, error)
+	RenameTag(id uuid.UUID, slug string) (*domain.Tag, error)
+	SetTranslation(id uuid.UUID, language, name string) (*domain.Tag, error)
+	MergeTags(sourceID, targetID uuid.UUID) (*domain.Tag, error)
+	DeleteTag(id uuid.UUID) error
+}
+
+type tagUsecase struct {
+	tagRepo      domain.TagRepository
+	comicUsecase ComicUsecase
+}
+
+func NewTagUsecase(tagRepo domain.TagRepository, comicUsecase ComicUsecase) TagUsecase {
+	return &tagUsecase{tagRepo, comicUsecase}
+}
+
+func (u *tagUsecase) ListTags(filter domain.TagFilter) ([]domain.Tag, int64, error) {
+	filter.Query = strings.TrimSpace(filter.Query)
+	if filter.Sort != domain.TagSortName {
+		filter.Sort = domain.TagSortPopular
+	}
+	return u.tagRepo.List(filter)
+}
+
+func (u *tagUsecase) GetTag(slug string) (*domain.Tag, error) {
+	return u.tagRepo.FindBySlug(strings.ToLower(strings.TrimSpace(slug)))
+}
+
+// ListComics browses the public catalog for one tag, with the same filters,
+// sorting and paging as the comic listing.
+func (u *tagUsecase) ListComics(viewer *domain.Principal, slug string, input ListComicsInput) (*domain.ComicPage, error) {
+	tag, err := u.GetTag(slug)
+	if err != nil {
+		return nil, err
+	}
+
+	input.Tag = tag.Slug
+	return u.comicUsecase.ListComics(viewer, input)
+}
+
+// RenameTag changes a tag's slug. Readers who hid the tag keep it hidden.
+func (u *tagUsecase) RenameTag(id uuid.UUID, slug string) (*domain.Tag, error) {
+	tag, err := u.tagRepo.FindByID(id)
+	if err != nil {
+		return nil, err
+	}
+
+	slug = utils.UnicodeSlug(slug)
+	if slug == "" {
+		return nil, ErrInvalidTagSlug
+	}
+	if slug == tag.Slug {
+		return tag, nil
+	}
+	existing, err := u.tagRepo.FindBySlug(slug)
+	if err == nil && existing.ID != id {
+		return nil, ErrTagSlugTaken
+	}
+	if err != nil && !errors.Is(err, domain.ErrNotFound) {
+		return nil, err
+	}
+
+	// The check above can race with another rename; the unique index has the
+	// final word.
+	if err := u.tagRepo.UpdateSlug(id, slug); err != nil {
+		if errors.Is(err, domain.ErrAlreadyExists) {
+			return nil, ErrTagSlugTaken
+		}
+		return nil, err
+	}
+	return u.tagRepo.FindByID(id)
+}
+
+// SetTranslation names the tag in language, adding a translation for
+// languages the tag has none in yet.
+func (u *tagUsecase) SetTranslation(id uuid.UUID, language, name string) (*domain.Tag, error) {
+	language = strings.ToLower(strings.TrimSpace(language))
+	if !tagLanguagePattern.MatchString(language) {
+		return nil, ErrInvalidTagLanguage
+	}
+	name = strings.TrimSpace(name)
+	if name == "" || utf8.RuneCountInString(name) > maxTagNameLength {
+		return nil, ErrInvalidTagName
+	}
+
+	if _, err := u.tagRepo.FindByID(id); err != nil {
+		return nil, err
+	}
+
+	if err := u.tagRepo.SaveTranslation(id, language, name); err != nil {
+		return nil, err
+	}
+	return u.tagRepo.FindByID(id)
+}
+
+// MergeTags folds a duplicate tag into target: its comics and any
+// translations target lacks move over, and the duplicate is deleted.
+func (u *tagUsecase) MergeTags(sourceID, targetID uuid.UUID) (*domain.Tag, error) {
+	if sourceID == targetID {
+		return nil, ErrMergeTagIntoItself
+	}
+	if _, err := u.tagRepo.FindByID(sourceID); err != nil {
+		return nil, err
+	}
+	if _, err := u.tagRepo.FindByID(targetID); err != nil {
+		return nil, err
+	}
+
+	if err := u.tagRepo.Merge(sourceID, targetID); err != nil {
+		return nil, err
+	}
+	return u.tagRepo.FindByID(targetID)
+}
+
+func (u *tagUsecase) DeleteTag(id uuid.UUID) error {
+	if _, err := u.tagRepo.FindByID(id); err != nil {
+		return err
+	}
+	return u.tagRepo.Delete(id)
+}
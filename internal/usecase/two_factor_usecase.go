@@ -0,0 +1,212 @@
+package usecase
+
+import (
+	"crypto/rand"
+	"encoding/base32"
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/pur108/talestoon-be/internal/domain"
+	"github.com/pur108/talestoon-be/pkg/totp"
+	"github.com/pur108/talestoon-be/pkg/utils"
+	"golang.org/x/crypto/bcrypt"
+)
+
+const (
+	totpIssuer        = "Talestoon"
+	recoveryCodeCount = 10
+)
+
+var ErrInvalidTwoFactorCode = errors.New("invalid two-factor code")
+
+type TwoFactorSetup struct {
+	Secret string `json:"secret"`
+	URI    string `json:"otpauth_uri"`
+}
+
+type TwoFactorUsecase interface {
+	Setup(userID uuid.UUID) (*TwoFactorSetup, error)
+	Enable(userID uuid.UUID, code string) ([]string, error)
+	Disable(userID uuid.UUID, password, code string) error
+	RegenerateRecoveryCodes(userID uuid.UUID, code string) ([]string, error)
+}
+
+type twoFactorUsecase struct {
+	userRepo  domain.UserRepository
+	tokenRepo domain.TokenRepository
+}
+
+func NewTwoFactorUsecase(userRepo domain.UserRepository, tokenRepo domain.TokenRepository) TwoFactorUsecase {
+	return &twoFactorUsecase{userRepo, tokenRepo}
+}
+
+// Setup generates a new TOTP secret for the user. Two-factor stays disabled
+// until Enable confirms the user's authenticator produces valid codes.
+func (u *twoFactorUsecase) Setup(userID uuid.UUID) (*TwoFactorSetup, error) {
+	user, err := u.userRepo.FindByID(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	if user.TwoFactorEnabledAt != nil {
+		return nil, errors.New("two-factor authentication is already enabled")
+	}
+
+	secret, err := totp.GenerateSecret()
+	if err != nil {
+		return nil, err
+	}
+
+	if err := u.userRepo.SetTwoFactorSecret(user.ID, secret); err != nil {
+		return nil, err
+	}
+
+	return &TwoFactorSetup{
+		Secret: secret,
+		URI:    totp.URI(totpIssuer, user.Email, secret),
+	}, nil
+}
+
+func (u *twoFactorUsecase) Enable(userID uuid.UUID, code string) ([]string, error) {
+	user, err := u.userRepo.FindByID(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	if user.TwoFactorEnabledAt != nil {
+		return nil, errors.New("two-factor authentication is already enabled")
+	}
+
+	if user.TwoFactorSecret == "" {
+		return nil, errors.New("two-factor setup has not been started")
+	}
+
+	if err := useTOTPCode(u.userRepo, user, code); err != nil {
+		return nil, err
+	}
+
+	codes, records, err := newRecoveryCodes(user.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := u.userRepo.EnableTwoFactor(user.ID, time.Now(), records); err != nil {
+		return nil, err
+	}
+
+	return codes, nil
+}
+
+func (u *twoFactorUsecase) Disable(userID uuid.UUID, password, code string) error {
+	user, err := u.userRepo.FindByID(userID)
+	if err != nil {
+		return err
+	}
+
+	if user.TwoFactorEnabledAt == nil {
+		return errors.New("two-factor authentication is not enabled")
+	}
+
+	if err := bcrypt.CompareHashAndPassword([]byte(user.PasswordHash), []byte(password)); err != nil {
+		return domain.ErrInvalidCredentials
+	}
+
+	if err := verifySecondFactor(u.userRepo, u.tokenRepo, user, code); err != nil {
+		return err
+	}
+
+	return u.userRepo.DisableTwoFactor(user.ID)
+}
+
+func (u *twoFactorUsecase) RegenerateRecoveryCodes(userID uuid.UUID, code string) ([]string, error) {
+	user, err := u.userRepo.FindByID(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	if user.TwoFactorEnabledAt == nil {
+		return nil, errors.New("two-factor authentication is not enabled")
+	}
+
+	if err := useTOTPCode(u.userRepo, user, code); err != nil {
+		return nil, err
+	}
+
+	codes, records, err := newRecoveryCodes(user.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := u.tokenRepo.ReplaceRecoveryCodes(user.ID, records); err != nil {
+		return nil, err
+	}
+
+	return codes, nil
+}
+
+// newRecoveryCodes generates a fresh set of recovery codes, returning the
+// plain codes to show once and the hashed records to store.
+func newRecoveryCodes(userID uuid.UUID) ([]string, []domain.RecoveryCode, error) {
+	codes := make([]string, 0, recoveryCodeCount)
+	records := make([]domain.RecoveryCode, 0, recoveryCodeCount)
+
+	for i := 0; i < recoveryCodeCount; i++ {
+		code, err := generateRecoveryCode()
+		if err != nil {
+			return nil, nil, err
+		}
+
+		codes = append(codes, code)
+		records = append(records, domain.RecoveryCode{
+			ID:        uuid.New(),
+			UserID:    userID,
+			CodeHash:  utils.HashToken(code),
+			CreatedAt: time.Now(),
+		})
+	}
+
+	return codes, records, nil
+}
+
+// generateRecoveryCode returns a code such as "k3b7q-x9m2d" using the base32
+// alphabet so it is easy to read back and type.
+func generateRecoveryCode() (string, error) {
+	b := make([]byte, 7)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	raw := strings.ToLower(base32.StdEncoding.EncodeToString(b))
+	return raw[:5] + "-" + raw[5:10], nil
+}
+
+// useTOTPCode accepts a current TOTP code once. A code whose time step is not
+// newer than the last accepted one is rejected, even inside the skew window.
+func useTOTPCode(userRepo domain.UserRepository, user *domain.User, code string) error {
+	step, ok := totp.ValidateStep(code, user.TwoFactorSecret, time.Now())
+	if !ok || step <= user.TwoFactorLastStep {
+		return ErrInvalidTwoFactorCode
+	}
+	if err := userRepo.UseTwoFactorStep(user.ID, step); err != nil {
+		if errors.Is(err, domain.ErrInvalidToken) {
+			return ErrInvalidTwoFactorCode
+		}
+		return err
+	}
+	return nil
+}
+
+// verifySecondFactor accepts either a current, not yet used TOTP code or an
+// unused recovery code, consuming whichever was supplied.
+func verifySecondFactor(userRepo domain.UserRepository, tokenRepo domain.TokenRepository, user *domain.User, code string) error {
+	code = strings.TrimSpace(code)
+	if _, ok := totp.ValidateStep(code, user.TwoFactorSecret, time.Now()); ok {
+		return useTOTPCode(userRepo, user, code)
+	}
+
+	if err := tokenRepo.ConsumeRecoveryCode(user.ID, utils.HashToken(strings.ToLower(code))); err != nil {
+		return ErrInvalidTwoFactorCode
+	}
+	return nil
+}
@@ -2,38 +2,294 @@ package usecase
 
 import (
 	"errors"
+	"net/url"
+	"os"
+	"strings"
+	"time"
+	"unicode/utf8"
 
 	"github.com/google/uuid"
 	"github.com/pur108/talestoon-be/internal/domain"
 )
 
+const (
+	maxDisplayNameLength = 50
+	maxBioLength         = 1000
+	maxProfileLinks      = 5
+	maxLinkLabelLength   = 30
+	maxHiddenFilters     = 50
+	maxAge               = 120
+)
+
+var (
+	ErrBirthDateSet     = errors.New("birth date has already been confirmed")
+	ErrAgeRequired      = errors.New("confirm that you are 18 or older before enabling NSFW content")
+	ErrInvalidBirthDate = errors.New("birth date must be in the past and within a plausible range")
+)
+
+// UpdateProfileInput holds the profile fields to change. Nil fields are left
+// untouched.
+type UpdateProfileInput struct {
+	DisplayName *string                  `json:"display_name"`
+	AvatarURL   *string                  `json:"avatar_url"`
+	Bio         *domain.MultilingualText `json:"bio"`
+	Links       *[]domain.ProfileLink    `json:"links"`
+}
+
+// UpdatePreferencesInput holds the reader preferences to change. Nil fields
+// are left untouched.
+type UpdatePreferencesInput struct {
+	Locale           *string   `json:"locale"`
+	ShowNSFW         *bool     `json:"show_nsfw"`
+	HiddenGenres     *[]string `json:"hidden_genres"`
+	HiddenTags       *[]string `json:"hidden_tags"`
+	ReadingDirection *string   `json:"reading_direction"`
+}
+
 type UserUsecase interface {
 	GetProfile(id uuid.UUID) (*domain.User, error)
-	BecomeCreator(id uuid.UUID) error
+	UpdateProfile(id uuid.UUID, input UpdateProfileInput) (*domain.User, error)
+	GetPublicProfile(username string) (*domain.PublicProfile, error)
+	GetPreferences(id uuid.UUID) (*domain.UserPreferences, error)
+	UpdatePreferences(id uuid.UUID, input UpdatePreferencesInput) (*domain.UserPreferences, error)
+	ConfirmAge(id uuid.UUID, birthDate time.Time) (*domain.User, error)
 }
 
 type userUsecase struct {
-	userRepo domain.UserRepository
+	userRepo        domain.UserRepository
+	preferencesRepo domain.PreferencesRepository
 }
 
-func NewUserUsecase(userRepo domain.UserRepository) UserUsecase {
-	return &userUsecase{userRepo}
+func NewUserUsecase(userRepo domain.UserRepository, preferencesRepo domain.PreferencesRepository) UserUsecase {
+	return &userUsecase{userRepo, preferencesRepo}
 }
 
 func (u *userUsecase) GetProfile(id uuid.UUID) (*domain.User, error) {
-	return u.userRepo.FindByID(id)
+	user, err := u.userRepo.FindByID(id)
+	if err != nil {
+		return nil, err
+	}
+
+	preferences, err := u.preferencesRepo.FindByUserID(id)
+	if err != nil {
+		return nil, err
+	}
+	user.Preferences = preferences
+
+	return user, nil
+}
+
+func (u *userUsecase) GetPreferences(id uuid.UUID) (*domain.UserPreferences, error) {
+	return u.preferencesRepo.FindByUserID(id)
+}
+
+func (u *userUsecase) UpdatePreferences(id uuid.UUID, input UpdatePreferencesInput) (*domain.UserPreferences, error) {
+	preferences, err := u.preferencesRepo.FindByUserID(id)
+	if err != nil {
+		return nil, err
+	}
+
+	if input.Locale != nil {
+		switch *input.Locale {
+		case domain.LocaleEn, domain.LocaleTh:
+			preferences.Locale = *input.Locale
+		default:
+			return nil, errors.New("locale must be en or th")
+		}
+	}
+
+	if input.ShowNSFW != nil {
+		if *input.ShowNSFW {
+			user, err := u.userRepo.FindByID(id)
+			if err != nil {
+				return nil, err
+			}
+			if !user.IsAdult(time.Now()) {
+				return nil, ErrAgeRequired
+			}
+		}
+		preferences.ShowNSFW = *input.ShowNSFW
+	}
+
+	if input.HiddenGenres != nil {
+		genres, err := normalizeFilters(*input.HiddenGenres, strings.TrimSpace)
+		if err != nil {
+			return nil, err
+		}
+		preferences.HiddenGenres = genres
+	}
+
+	if input.HiddenTags != nil {
+		tags, err := normalizeFilters(*input.HiddenTags, func(tag string) string {
+			return strings.ToLower(strings.TrimSpace(tag))
+		})
+		if err != nil {
+			return nil, err
+		}
+		preferences.HiddenTags = tags
+	}
+
+	if input.ReadingDirection != nil {
+		switch *input.ReadingDirection {
+		case domain.ReadingVertical, domain.ReadingLTR, domain.ReadingRTL:
+			preferences.ReadingDirection = *input.ReadingDirection
+		default:
+			return nil, errors.New("reading direction must be vertical, ltr or rtl")
+		}
+	}
+
+	if err := u.preferencesRepo.Save(preferences); err != nil {
+		return nil, err
+	}
+
+	return preferences, nil
 }
 
-func (u *userUsecase) BecomeCreator(id uuid.UUID) error {
+func (u *userUsecase) UpdateProfile(id uuid.UUID, input UpdateProfileInput) (*domain.User, error) {
 	user, err := u.userRepo.FindByID(id)
 	if err != nil {
-		return err
+		return nil, err
+	}
+
+	if input.DisplayName != nil {
+		name := strings.TrimSpace(*input.DisplayName)
+		if utf8.RuneCountInString(name) > maxDisplayNameLength {
+			return nil, errors.New("display name must be at most 50 characters")
+		}
+		user.DisplayName = name
+	}
+
+	if input.AvatarURL != nil {
+		avatarURL := strings.TrimSpace(*input.AvatarURL)
+		if avatarURL != "" && !isUploadedImageURL(avatarURL) {
+			return nil, errors.New("avatar must be an image uploaded through /api/upload")
+		}
+		user.AvatarURL = avatarURL
+	}
+
+	if input.Bio != nil {
+		if utf8.RuneCountInString(input.Bio.En) > maxBioLength || utf8.RuneCountInString(input.Bio.Th) > maxBioLength {
+			return nil, errors.New("bio must be at most 1000 characters per language")
+		}
+		user.Bio = *input.Bio
+	}
+
+	if input.Links != nil {
+		links, err := validateProfileLinks(*input.Links)
+		if err != nil {
+			return nil, err
+		}
+		user.Links = links
+	}
+
+	if err := u.userRepo.UpdateProfile(user); err != nil {
+		return nil, err
+	}
+
+	return user, nil
+}
+
+// ConfirmAge records the user's birth date. It can only be set once so that
+// a minor cannot simply retry with an earlier date.
+func (u *userUsecase) ConfirmAge(id uuid.UUID, birthDate time.Time) (*domain.User, error) {
+	user, err := u.userRepo.FindByID(id)
+	if err != nil {
+		return nil, err
+	}
+
+	if user.BirthDate != nil {
+		return nil, ErrBirthDateSet
+	}
+
+	now := time.Now()
+	if birthDate.After(now) || birthDate.Before(now.AddDate(-maxAge, 0, 0)) {
+		return nil, ErrInvalidBirthDate
+	}
+
+	if err := u.userRepo.SetBirthDate(id, birthDate, now); err != nil {
+		if errors.Is(err, domain.ErrNotFound) {
+			return nil, ErrBirthDateSet
+		}
+		return nil, err
+	}
+
+	user.BirthDate = &birthDate
+	user.AgeConfirmedAt = &now
+	return user, nil
+}
+
+func (u *userUsecase) GetPublicProfile(username string) (*domain.PublicProfile, error) {
+	user, err := u.userRepo.FindByUsername(username)
+	if err != nil || user.AnonymizedAt != nil {
+		return nil, domain.ErrNotFound
+	}
+	return user.PublicProfile(), nil
+}
+
+// normalizeFilters cleans a list of hidden genres or tag slugs, dropping
+// blanks and duplicates.
+func normalizeFilters(values []string, normalize func(string) string) ([]string, error) {
+	if len(values) > maxHiddenFilters {
+		return nil, errors.New("at most 50 hidden genres or tags are allowed")
+	}
+
+	seen := make(map[string]bool, len(values))
+	cleaned := make([]string, 0, len(values))
+	for _, value := range values {
+		value = normalize(value)
+		if value == "" || seen[value] {
+			continue
+		}
+		seen[value] = true
+		cleaned = append(cleaned, value)
+	}
+
+	return cleaned, nil
+}
+
+func validateProfileLinks(links []domain.ProfileLink) ([]domain.ProfileLink, error) {
+	if len(links) > maxProfileLinks {
+		return nil, errors.New("at most 5 links are allowed")
+	}
+
+	cleaned := make([]domain.ProfileLink, 0, len(links))
+	for _, link := range links {
+		label := strings.TrimSpace(link.Label)
+		if label == "" || utf8.RuneCountInString(label) > maxLinkLabelLength {
+			return nil, errors.New("link labels must be 1 to 30 characters")
+		}
+		if !isWebURL(link.URL) {
+			return nil, errors.New("links must be http or https URLs")
+		}
+		cleaned = append(cleaned, domain.ProfileLink{Label: label, URL: strings.TrimSpace(link.URL)})
+	}
+
+	return cleaned, nil
+}
+
+func isWebURL(raw string) bool {
+	parsed, err := url.Parse(strings.TrimSpace(raw))
+	return err == nil && (parsed.Scheme == "https" || parsed.Scheme == "http") && parsed.Host != ""
+}
+
+// isUploadedImageURL reports whether raw points at the public media bucket
+// that the upload endpoint writes to.
+func isUploadedImageURL(raw string) bool {
+	if !isWebURL(raw) {
+		return false
+	}
+
+	parsed, _ := url.Parse(raw)
+	if !strings.HasPrefix(parsed.Path, "/storage/v1/object/public/media/") {
+		return false
 	}
 
-	if user.Role == domain.RoleCreator || user.Role == domain.RoleAdmin {
-		return errors.New("user is already a creator or admin")
+	if projectURL := os.Getenv("SUPABASE_PROJECT_URL"); projectURL != "" {
+		project, err := url.Parse(projectURL)
+		if err != nil || project.Host != parsed.Host {
+			return false
+		}
 	}
 
-	user.Role = domain.RoleCreator
-	return u.userRepo.Update(user)
+	return true
 }
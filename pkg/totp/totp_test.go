@@ -0,0 +1,74 @@
+package totp
+
+import (
+	"testing"
+	"time"
+)
+
+// rfcSecret is the RFC 6238 SHA-1 test key "12345678901234567890" in base32.
+const rfcSecret = "GEZDGNBVGY3TQOJQGEZDGNBVGY3TQOJQ"
+
+func TestCode(t *testing.T) {
+	// Expected values are the last six digits of the RFC 6238 test vectors.
+	tests := []struct {
+		unix int64
+		want string
+	}{
+		{59, "287082"},
+		{1111111109, "081804"},
+		{1111111111, "050471"},
+		{1234567890, "005924"},
+		{2000000000, "279037"},
+	}
+
+	for _, tt := range tests {
+		got, err := Code(rfcSecret, time.Unix(tt.unix, 0))
+		if err != nil {
+			t.Fatalf("Code(%d) returned error: %v", tt.unix, err)
+		}
+		if got != tt.want {
+			t.Errorf("Code(%d) = %s; want %s", tt.unix, got, tt.want)
+		}
+	}
+}
+
+func TestValidate(t *testing.T) {
+	now := time.Unix(1234567890, 0)
+
+	if !Validate("005924", rfcSecret, now) {
+		t.Error("expected current code to be valid")
+	}
+	if !Validate("005924", rfcSecret, now.Add(period*time.Second)) {
+		t.Error("expected previous period's code to be accepted")
+	}
+	if Validate("005924", rfcSecret, now.Add(3*period*time.Second)) {
+		t.Error("expected code outside the skew window to be rejected")
+	}
+	if Validate("12345", rfcSecret, now) {
+		t.Error("expected short code to be rejected")
+	}
+}
+
+func TestValidateStep(t *testing.T) {
+	now := time.Unix(1234567890, 0)
+	want := now.Unix() / period
+
+	if step, ok := ValidateStep("005924", rfcSecret, now.Add(period*time.Second)); !ok || step != want {
+		t.Errorf("ValidateStep = %d, %v; want %d, true", step, ok, want)
+	}
+}
+
+func TestGenerateSecret(t *testing.T) {
+	secret, err := GenerateSecret()
+	if err != nil {
+		t.Fatalf("GenerateSecret returned error: %v", err)
+	}
+
+	code, err := Code(secret, time.Now())
+	if err != nil {
+		t.Fatalf("Code returned error for generated secret: %v", err)
+	}
+	if !Validate(code, secret, time.Now()) {
+		t.Error("expected code for generated secret to validate")
+	}
+}
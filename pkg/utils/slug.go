@@ -3,6 +3,7 @@ package utils
 import (
 	"regexp"
 	"strings"
+	"unicode"
 )
 
 func SimpleSlug(s string) string {
@@ -10,4 +11,25 @@ func SimpleSlug(s string) string {
     s = strings.TrimSpace(s)
     s = strings.ReplaceAll(s, " ", "-")
     return regexp.MustCompile(`[^a-z0-9\-]`).ReplaceAllString(s, "")
-}
\ No newline at end of file
+}
+
+// UnicodeSlug builds a slug that keeps letters in any script, so Thai names
+// that SimpleSlug would strip to nothing still get a usable slug. Thai vowel
+// and tone marks are kept because they are part of the word.
+func UnicodeSlug(s string) string {
+    var b strings.Builder
+    dash := false
+    for _, r := range strings.ToLower(strings.TrimSpace(s)) {
+        switch {
+        case unicode.IsLetter(r) || unicode.IsDigit(r) || unicode.IsMark(r):
+            b.WriteRune(r)
+            dash = false
+        case unicode.IsSpace(r) || r == '-' || r == '_':
+            if !dash && b.Len() > 0 {
+                b.WriteRune('-')
+                dash = true
+            }
+        }
+    }
+    return strings.TrimSuffix(b.String(), "-")
+}